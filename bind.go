@@ -0,0 +1,228 @@
+package oldflag
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// -- encoding.TextUnmarshaler Value
+type textValue struct {
+	v encoding.TextUnmarshaler
+}
+
+func (t textValue) Set(s string) error { return t.v.UnmarshalText([]byte(s)) }
+
+func (t textValue) Get() interface{} { return t.v }
+
+func (t textValue) String() string {
+	if t.v == nil {
+		return ""
+	}
+	if m, ok := t.v.(encoding.TextMarshaler); ok {
+		b, err := m.MarshalText()
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	}
+	return fmt.Sprint(t.v)
+}
+
+// Bind defines a flag for each exported field of the struct v points to,
+// so that after Parse the struct holds the values of the flags. A field is
+// bound by the long name given in its flag tag, or else by its name in
+// lower case with - between words, as DryRun is by --dry-run. Its tags may
+// also give:
+//	flag:"c"          the rune name, and no long name
+//	flag:"c,count"    the rune name and the long name
+//	flag:"-"          no flag at all
+//	usage:"..."       the usage message, with a back-quoted name as usual
+//	default:"2"       the default value, instead of the value of the field
+//	env:"COUNT"       the environment variable, as in Flag.Env
+//	sep:","           the separator of a slice or map, as in Flag.Separator
+//	required:"true"   that the flag is required, as in Flag.Required
+// A field may be of any type implementing Value or encoding.TextUnmarshaler
+// through a pointer, of any of the types of the flags defined by this
+// package, such as int, time.Duration, []string or map[string]string, or a
+// struct, whose fields are bound in turn, with long names prefixed by that
+// of the struct and a dot, as --db.host, unless it is embedded. Unexported
+// fields are skipped, but the exported fields of an unexported embedded
+// struct are bound as those of an exported one.
+//
+// Bind returns an error, defining no more flags, if a field cannot be bound.
+func (f *FlagSet) Bind(v interface{}) error {
+	p := reflect.ValueOf(v)
+	if p.Kind() != reflect.Ptr || p.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("cannot bind flags to %T: not a pointer to a struct", v)
+	}
+	return f.bindStruct(p.Elem(), "")
+}
+
+// Bind defines a command-line flag for each exported field of the struct
+// v points to. See FlagSet.Bind for details.
+func Bind(v interface{}) error {
+	return CommandLine.Bind(v)
+}
+
+// bindStruct binds the fields of the struct s, prefixing their long names
+// with prefix.
+func (f *FlagSet) bindStruct(s reflect.Value, prefix string) error {
+	t := s.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("flag")
+		if tag == "-" {
+			continue
+		}
+		if field.PkgPath != "" {
+			// The exported fields of an unexported embedded struct are
+			// promoted; its other fields cannot be set.
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				if err := f.bindStruct(s.Field(i), prefix); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		var name rune
+		long := kebabCase(field.Name)
+		if tag != "" {
+			short := tag
+			if i := strings.IndexByte(tag, ','); i >= 0 {
+				short, long = tag[:i], tag[i+1:]
+			} else {
+				long = ""
+			}
+			if short != "" {
+				r, size := utf8.DecodeRuneInString(short)
+				if size != len(short) {
+					return fmt.Errorf("field %s: flag name %q is not a single rune", field.Name, short)
+				}
+				name = r
+			}
+		}
+		if long != "" {
+			long = prefix + long
+		}
+
+		fv := s.Field(i)
+		value := bindValue(fv.Addr().Interface())
+		if value == nil && fv.Kind() == reflect.Struct {
+			nested := prefix
+			if !field.Anonymous {
+				nested = long + "."
+			}
+			if err := f.bindStruct(fv, nested); err != nil {
+				return err
+			}
+			continue
+		}
+		if value == nil {
+			return fmt.Errorf("field %s: cannot bind a flag to type %s", field.Name, field.Type)
+		}
+		if name == 0 && long == "" {
+			return fmt.Errorf("field %s: no flag name", field.Name)
+		}
+
+		sep := field.Tag.Get("sep")
+		if def, ok := field.Tag.Lookup("default"); ok {
+			if rv, ok := value.(repeatableFlag); ok && rv.IsRepeatable() {
+				rv.Reset()
+				values := []string{def}
+				if sep != "" {
+					values = strings.Split(def, sep)
+				}
+				for _, d := range values {
+					if err := rv.Set(d); err != nil {
+						return fmt.Errorf("field %s: invalid default %q: %v", field.Name, def, err)
+					}
+				}
+			} else if err := value.Set(def); err != nil {
+				return fmt.Errorf("field %s: invalid default %q: %v", field.Name, def, err)
+			}
+		}
+		f.VarLong(value, name, long, field.Tag.Get("usage"))
+		flag := f.lookupBound(name, long)
+		flag.Env = field.Tag.Get("env")
+		flag.Separator = sep
+		flag.Required = field.Tag.Get("required") == "true"
+	}
+	return nil
+}
+
+// lookupBound returns the flag just defined with name and long.
+func (f *FlagSet) lookupBound(name rune, long string) *Flag {
+	if name != 0 {
+		return f.formal[name]
+	}
+	return f.formalLong[long]
+}
+
+// bindValue returns the Value that stores into the field p points to, or
+// nil if there is none.
+func bindValue(p interface{}) Value {
+	switch p := p.(type) {
+	case Value:
+		return p
+	case encoding.TextUnmarshaler:
+		return textValue{p}
+	case *bool:
+		return (*boolValue)(p)
+	case *int:
+		return (*intValue)(p)
+	case *int64:
+		return (*int64Value)(p)
+	case *uint:
+		return (*uintValue)(p)
+	case *uint64:
+		return (*uint64Value)(p)
+	case *string:
+		return (*stringValue)(p)
+	case *float64:
+		return (*float64Value)(p)
+	case *time.Duration:
+		return (*durationValue)(p)
+	case *[]string:
+		return (*stringSliceValue)(p)
+	case *[]int:
+		return (*intSliceValue)(p)
+	case *[]time.Duration:
+		return (*durationSliceValue)(p)
+	case *map[string]string:
+		if *p == nil {
+			*p = make(map[string]string)
+		}
+		return (*stringMapValue)(p)
+	case *map[string]int:
+		if *p == nil {
+			*p = make(map[string]int)
+		}
+		return (*intMapValue)(p)
+	}
+	return nil
+}
+
+// kebabCase turns a Go identifier into a long flag name, such as DryRun
+// into dry-run and HTTPPort into http-port.
+func kebabCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			// A new word starts at an upper-case letter following a
+			// lower-case one, or followed by one in an acronym.
+			if i > 0 && (unicode.IsLower(runes[i-1]) ||
+				i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1])) {
+				b.WriteByte('-')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
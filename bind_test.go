@@ -0,0 +1,71 @@
+package oldflag
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestBind(t *testing.T) {
+	var config struct {
+		Count   int               `flag:"c,count" default:"2" usage:"stop after n lines"`
+		Verbose bool              `flag:"v"`
+		DryRun  bool              `usage:"do nothing"`
+		Timeout time.Duration     `default:"5s"`
+		Include []string          `flag:"I" sep:","`
+		Define  map[string]string `flag:"D"`
+		Addr    net.IP            `default:"127.0.0.1"`
+		DB      struct {
+			Host string `default:"localhost"`
+			Port int    `flag:"p,port"`
+		}
+		Skip int `flag:"-"`
+	}
+	f := NewFlagSet("test", ContinueOnError)
+	if err := f.Bind(&config); err != nil {
+		t.Fatal(err)
+	}
+	if config.Count != 2 || config.Timeout != 5*time.Second || config.Addr.String() != "127.0.0.1" {
+		t.Errorf("defaults not set: %+v", config)
+	}
+	if f.LookupLong("skip") != nil {
+		t.Errorf("field tagged - was bound")
+	}
+
+	args := []string{"-vc5", "--dry-run", "-Ia,b", "-DX=1", "--addr", "10.0.0.1", "--db.host=db", "-p", "9"}
+	if err := f.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	got := fmt.Sprintf("%d %v %v %v %v %v %v %s %d", config.Count, config.Verbose, config.DryRun,
+		config.Timeout, config.Include, config.Define, config.Addr, config.DB.Host, config.DB.Port)
+	want := "5 true true 5s [a b] map[X:1] 10.0.0.1 db 9"
+	if got != want {
+		t.Errorf("after Parse(%q):\n got %s\nwant %s", args, got, want)
+	}
+
+	var embedded struct {
+		bindInner
+		Y int
+	}
+	f = NewFlagSet("test", ContinueOnError)
+	if err := f.Bind(&embedded); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Parse([]string{"--x=1", "--y=2"}); err != nil {
+		t.Fatal(err)
+	}
+	if embedded.X != 1 || embedded.Y != 2 || f.LookupLong("hidden") != nil {
+		t.Errorf("after binding an unexported embedded struct: %+v", embedded)
+	}
+
+	var bad struct{ C chan int }
+	if err := f.Bind(&bad); err == nil {
+		t.Errorf("Bind of a chan field succeeded")
+	}
+}
+
+type bindInner struct {
+	X      int
+	hidden int
+}
@@ -178,6 +178,39 @@ func (d *durationValue) Get() interface{} { return time.Duration(*d) }
 
 func (d *durationValue) String() string { return (*time.Duration)(d).String() }
 
+// -- count Value
+type countValue int
+
+func newCountValue(val int, p *int) *countValue {
+	*p = val
+	return (*countValue)(p)
+}
+
+// Set increments the count for "true", as the flag given alone sets it,
+// clears it for "false", and otherwise sets it to the given number.
+func (c *countValue) Set(s string) error {
+	switch s {
+	case "true":
+		*c++
+		return nil
+	case "false":
+		*c = 0
+		return nil
+	}
+	v, err := strconv.ParseInt(s, 0, strconv.IntSize)
+	if err != nil {
+		err = numError(err)
+	}
+	*c = countValue(v)
+	return err
+}
+
+func (c *countValue) Get() interface{} { return int(*c) }
+
+func (c *countValue) String() string { return strconv.Itoa(int(*c)) }
+
+func (c *countValue) IsBoolFlag() bool { return true }
+
 // BoolVar defines a bool flag with specified name, default value, and usage string.
 // The argument p points to a bool variable in which to store the value of the flag.
 func (f *FlagSet) BoolVar(p *bool, name rune, value bool, usage string) {
@@ -204,6 +237,32 @@ func Bool(name rune, value bool, usage string) *bool {
 	return CommandLine.Bool(name, value, usage)
 }
 
+// BoolVarLong defines a bool flag with specified name, long name, default value, and usage string.
+// The argument p points to a bool variable in which to store the value of the flag.
+func (f *FlagSet) BoolVarLong(p *bool, name rune, long string, value bool, usage string) {
+	f.VarLong(newBoolValue(value, p), name, long, usage)
+}
+
+// BoolVarLong defines a bool flag with specified name, long name, default value, and usage string.
+// The argument p points to a bool variable in which to store the value of the flag.
+func BoolVarLong(p *bool, name rune, long string, value bool, usage string) {
+	CommandLine.VarLong(newBoolValue(value, p), name, long, usage)
+}
+
+// BoolLong defines a bool flag with specified name, long name, default value, and usage string.
+// The return value is the address of a bool variable that stores the value of the flag.
+func (f *FlagSet) BoolLong(name rune, long string, value bool, usage string) *bool {
+	p := new(bool)
+	f.BoolVarLong(p, name, long, value, usage)
+	return p
+}
+
+// BoolLong defines a bool flag with specified name, long name, default value, and usage string.
+// The return value is the address of a bool variable that stores the value of the flag.
+func BoolLong(name rune, long string, value bool, usage string) *bool {
+	return CommandLine.BoolLong(name, long, value, usage)
+}
+
 // IntVar defines an int flag with specified name, default value, and usage string.
 // The argument p points to an int variable in which to store the value of the flag.
 func (f *FlagSet) IntVar(p *int, name rune, value int, usage string) {
@@ -230,6 +289,32 @@ func Int(name rune, value int, usage string) *int {
 	return CommandLine.Int(name, value, usage)
 }
 
+// IntVarLong defines an int flag with specified name, long name, default value, and usage string.
+// The argument p points to an int variable in which to store the value of the flag.
+func (f *FlagSet) IntVarLong(p *int, name rune, long string, value int, usage string) {
+	f.VarLong(newIntValue(value, p), name, long, usage)
+}
+
+// IntVarLong defines an int flag with specified name, long name, default value, and usage string.
+// The argument p points to an int variable in which to store the value of the flag.
+func IntVarLong(p *int, name rune, long string, value int, usage string) {
+	CommandLine.VarLong(newIntValue(value, p), name, long, usage)
+}
+
+// IntLong defines an int flag with specified name, long name, default value, and usage string.
+// The return value is the address of an int variable that stores the value of the flag.
+func (f *FlagSet) IntLong(name rune, long string, value int, usage string) *int {
+	p := new(int)
+	f.IntVarLong(p, name, long, value, usage)
+	return p
+}
+
+// IntLong defines an int flag with specified name, long name, default value, and usage string.
+// The return value is the address of an int variable that stores the value of the flag.
+func IntLong(name rune, long string, value int, usage string) *int {
+	return CommandLine.IntLong(name, long, value, usage)
+}
+
 // Int64Var defines an int64 flag with specified name, default value, and usage string.
 // The argument p points to an int64 variable in which to store the value of the flag.
 func (f *FlagSet) Int64Var(p *int64, name rune, value int64, usage string) {
@@ -256,6 +341,32 @@ func Int64(name rune, value int64, usage string) *int64 {
 	return CommandLine.Int64(name, value, usage)
 }
 
+// Int64VarLong defines an int64 flag with specified name, long name, default value, and usage string.
+// The argument p points to an int64 variable in which to store the value of the flag.
+func (f *FlagSet) Int64VarLong(p *int64, name rune, long string, value int64, usage string) {
+	f.VarLong(newInt64Value(value, p), name, long, usage)
+}
+
+// Int64VarLong defines an int64 flag with specified name, long name, default value, and usage string.
+// The argument p points to an int64 variable in which to store the value of the flag.
+func Int64VarLong(p *int64, name rune, long string, value int64, usage string) {
+	CommandLine.VarLong(newInt64Value(value, p), name, long, usage)
+}
+
+// Int64Long defines an int64 flag with specified name, long name, default value, and usage string.
+// The return value is the address of an int64 variable that stores the value of the flag.
+func (f *FlagSet) Int64Long(name rune, long string, value int64, usage string) *int64 {
+	p := new(int64)
+	f.Int64VarLong(p, name, long, value, usage)
+	return p
+}
+
+// Int64Long defines an int64 flag with specified name, long name, default value, and usage string.
+// The return value is the address of an int64 variable that stores the value of the flag.
+func Int64Long(name rune, long string, value int64, usage string) *int64 {
+	return CommandLine.Int64Long(name, long, value, usage)
+}
+
 // UintVar defines a uint flag with specified name, default value, and usage string.
 // The argument p points to a uint variable in which to store the value of the flag.
 func (f *FlagSet) UintVar(p *uint, name rune, value uint, usage string) {
@@ -282,6 +393,32 @@ func Uint(name rune, value uint, usage string) *uint {
 	return CommandLine.Uint(name, value, usage)
 }
 
+// UintVarLong defines a uint flag with specified name, long name, default value, and usage string.
+// The argument p points to a uint variable in which to store the value of the flag.
+func (f *FlagSet) UintVarLong(p *uint, name rune, long string, value uint, usage string) {
+	f.VarLong(newUintValue(value, p), name, long, usage)
+}
+
+// UintVarLong defines a uint flag with specified name, long name, default value, and usage string.
+// The argument p points to a uint variable in which to store the value of the flag.
+func UintVarLong(p *uint, name rune, long string, value uint, usage string) {
+	CommandLine.VarLong(newUintValue(value, p), name, long, usage)
+}
+
+// UintLong defines a uint flag with specified name, long name, default value, and usage string.
+// The return value is the address of a uint variable that stores the value of the flag.
+func (f *FlagSet) UintLong(name rune, long string, value uint, usage string) *uint {
+	p := new(uint)
+	f.UintVarLong(p, name, long, value, usage)
+	return p
+}
+
+// UintLong defines a uint flag with specified name, long name, default value, and usage string.
+// The return value is the address of a uint variable that stores the value of the flag.
+func UintLong(name rune, long string, value uint, usage string) *uint {
+	return CommandLine.UintLong(name, long, value, usage)
+}
+
 // Uint64Var defines a uint64 flag with specified name, default value, and usage string.
 // The argument p points to a uint64 variable in which to store the value of the flag.
 func (f *FlagSet) Uint64Var(p *uint64, name rune, value uint64, usage string) {
@@ -308,6 +445,32 @@ func Uint64(name rune, value uint64, usage string) *uint64 {
 	return CommandLine.Uint64(name, value, usage)
 }
 
+// Uint64VarLong defines a uint64 flag with specified name, long name, default value, and usage string.
+// The argument p points to a uint64 variable in which to store the value of the flag.
+func (f *FlagSet) Uint64VarLong(p *uint64, name rune, long string, value uint64, usage string) {
+	f.VarLong(newUint64Value(value, p), name, long, usage)
+}
+
+// Uint64VarLong defines a uint64 flag with specified name, long name, default value, and usage string.
+// The argument p points to a uint64 variable in which to store the value of the flag.
+func Uint64VarLong(p *uint64, name rune, long string, value uint64, usage string) {
+	CommandLine.VarLong(newUint64Value(value, p), name, long, usage)
+}
+
+// Uint64Long defines a uint64 flag with specified name, long name, default value, and usage string.
+// The return value is the address of a uint64 variable that stores the value of the flag.
+func (f *FlagSet) Uint64Long(name rune, long string, value uint64, usage string) *uint64 {
+	p := new(uint64)
+	f.Uint64VarLong(p, name, long, value, usage)
+	return p
+}
+
+// Uint64Long defines a uint64 flag with specified name, long name, default value, and usage string.
+// The return value is the address of a uint64 variable that stores the value of the flag.
+func Uint64Long(name rune, long string, value uint64, usage string) *uint64 {
+	return CommandLine.Uint64Long(name, long, value, usage)
+}
+
 // StringVar defines a string flag with specified name, default value, and usage string.
 // The argument p points to a string variable in which to store the value of the flag.
 func (f *FlagSet) StringVar(p *string, name rune, value string, usage string) {
@@ -334,6 +497,32 @@ func String(name rune, value string, usage string) *string {
 	return CommandLine.String(name, value, usage)
 }
 
+// StringVarLong defines a string flag with specified name, long name, default value, and usage string.
+// The argument p points to a string variable in which to store the value of the flag.
+func (f *FlagSet) StringVarLong(p *string, name rune, long string, value string, usage string) {
+	f.VarLong(newStringValue(value, p), name, long, usage)
+}
+
+// StringVarLong defines a string flag with specified name, long name, default value, and usage string.
+// The argument p points to a string variable in which to store the value of the flag.
+func StringVarLong(p *string, name rune, long string, value string, usage string) {
+	CommandLine.VarLong(newStringValue(value, p), name, long, usage)
+}
+
+// StringLong defines a string flag with specified name, long name, default value, and usage string.
+// The return value is the address of a string variable that stores the value of the flag.
+func (f *FlagSet) StringLong(name rune, long string, value string, usage string) *string {
+	p := new(string)
+	f.StringVarLong(p, name, long, value, usage)
+	return p
+}
+
+// StringLong defines a string flag with specified name, long name, default value, and usage string.
+// The return value is the address of a string variable that stores the value of the flag.
+func StringLong(name rune, long string, value string, usage string) *string {
+	return CommandLine.StringLong(name, long, value, usage)
+}
+
 // Float64Var defines a float64 flag with specified name, default value, and usage string.
 // The argument p points to a float64 variable in which to store the value of the flag.
 func (f *FlagSet) Float64Var(p *float64, name rune, value float64, usage string) {
@@ -360,6 +549,32 @@ func Float64(name rune, value float64, usage string) *float64 {
 	return CommandLine.Float64(name, value, usage)
 }
 
+// Float64VarLong defines a float64 flag with specified name, long name, default value, and usage string.
+// The argument p points to a float64 variable in which to store the value of the flag.
+func (f *FlagSet) Float64VarLong(p *float64, name rune, long string, value float64, usage string) {
+	f.VarLong(newFloat64Value(value, p), name, long, usage)
+}
+
+// Float64VarLong defines a float64 flag with specified name, long name, default value, and usage string.
+// The argument p points to a float64 variable in which to store the value of the flag.
+func Float64VarLong(p *float64, name rune, long string, value float64, usage string) {
+	CommandLine.VarLong(newFloat64Value(value, p), name, long, usage)
+}
+
+// Float64Long defines a float64 flag with specified name, long name, default value, and usage string.
+// The return value is the address of a float64 variable that stores the value of the flag.
+func (f *FlagSet) Float64Long(name rune, long string, value float64, usage string) *float64 {
+	p := new(float64)
+	f.Float64VarLong(p, name, long, value, usage)
+	return p
+}
+
+// Float64Long defines a float64 flag with specified name, long name, default value, and usage string.
+// The return value is the address of a float64 variable that stores the value of the flag.
+func Float64Long(name rune, long string, value float64, usage string) *float64 {
+	return CommandLine.Float64Long(name, long, value, usage)
+}
+
 // DurationVar defines a time.Duration flag with specified name, default value, and usage string.
 // The argument p points to a time.Duration variable in which to store the value of the flag.
 // The flag accepts a value acceptable to time.ParseDuration.
@@ -389,3 +604,93 @@ func (f *FlagSet) Duration(name rune, value time.Duration, usage string) *time.D
 func Duration(name rune, value time.Duration, usage string) *time.Duration {
 	return CommandLine.Duration(name, value, usage)
 }
+
+// DurationVarLong defines a time.Duration flag with specified name, long name, default value, and usage string.
+// The argument p points to a time.Duration variable in which to store the value of the flag.
+// The flag accepts a value acceptable to time.ParseDuration.
+func (f *FlagSet) DurationVarLong(p *time.Duration, name rune, long string, value time.Duration, usage string) {
+	f.VarLong(newDurationValue(value, p), name, long, usage)
+}
+
+// DurationVarLong defines a time.Duration flag with specified name, long name, default value, and usage string.
+// The argument p points to a time.Duration variable in which to store the value of the flag.
+// The flag accepts a value acceptable to time.ParseDuration.
+func DurationVarLong(p *time.Duration, name rune, long string, value time.Duration, usage string) {
+	CommandLine.VarLong(newDurationValue(value, p), name, long, usage)
+}
+
+// DurationLong defines a time.Duration flag with specified name, long name, default value, and usage string.
+// The return value is the address of a time.Duration variable that stores the value of the flag.
+// The flag accepts a value acceptable to time.ParseDuration.
+func (f *FlagSet) DurationLong(name rune, long string, value time.Duration, usage string) *time.Duration {
+	p := new(time.Duration)
+	f.DurationVarLong(p, name, long, value, usage)
+	return p
+}
+
+// DurationLong defines a time.Duration flag with specified name, long name, default value, and usage string.
+// The return value is the address of a time.Duration variable that stores the value of the flag.
+// The flag accepts a value acceptable to time.ParseDuration.
+func DurationLong(name rune, long string, value time.Duration, usage string) *time.Duration {
+	return CommandLine.DurationLong(name, long, value, usage)
+}
+
+// CountVar defines a count flag with specified name, default value, and usage string.
+// The argument p points to an int variable in which to store the value of the flag.
+// Each occurrence of the flag, as in -vvv, increments the count; -v=3 sets it and -v=0 clears it.
+func (f *FlagSet) CountVar(p *int, name rune, value int, usage string) {
+	f.Var(newCountValue(value, p), name, usage)
+}
+
+// CountVar defines a count flag with specified name, default value, and usage string.
+// The argument p points to an int variable in which to store the value of the flag.
+// Each occurrence of the flag, as in -vvv, increments the count; -v=3 sets it and -v=0 clears it.
+func CountVar(p *int, name rune, value int, usage string) {
+	CommandLine.Var(newCountValue(value, p), name, usage)
+}
+
+// Count defines a count flag with specified name, default value, and usage string.
+// The return value is the address of an int variable that stores the value of the flag.
+// Each occurrence of the flag, as in -vvv, increments the count; -v=3 sets it and -v=0 clears it.
+func (f *FlagSet) Count(name rune, value int, usage string) *int {
+	p := new(int)
+	f.CountVar(p, name, value, usage)
+	return p
+}
+
+// Count defines a count flag with specified name, default value, and usage string.
+// The return value is the address of an int variable that stores the value of the flag.
+// Each occurrence of the flag, as in -vvv, increments the count; -v=3 sets it and -v=0 clears it.
+func Count(name rune, value int, usage string) *int {
+	return CommandLine.Count(name, value, usage)
+}
+
+// CountVarLong defines a count flag with specified name, long name, default value, and usage string.
+// The argument p points to an int variable in which to store the value of the flag.
+// Each occurrence of the flag, as in -vvv, increments the count; -v=3 sets it and -v=0 clears it.
+func (f *FlagSet) CountVarLong(p *int, name rune, long string, value int, usage string) {
+	f.VarLong(newCountValue(value, p), name, long, usage)
+}
+
+// CountVarLong defines a count flag with specified name, long name, default value, and usage string.
+// The argument p points to an int variable in which to store the value of the flag.
+// Each occurrence of the flag, as in -vvv, increments the count; -v=3 sets it and -v=0 clears it.
+func CountVarLong(p *int, name rune, long string, value int, usage string) {
+	CommandLine.VarLong(newCountValue(value, p), name, long, usage)
+}
+
+// CountLong defines a count flag with specified name, long name, default value, and usage string.
+// The return value is the address of an int variable that stores the value of the flag.
+// Each occurrence of the flag, as in -vvv, increments the count; -v=3 sets it and -v=0 clears it.
+func (f *FlagSet) CountLong(name rune, long string, value int, usage string) *int {
+	p := new(int)
+	f.CountVarLong(p, name, long, value, usage)
+	return p
+}
+
+// CountLong defines a count flag with specified name, long name, default value, and usage string.
+// The return value is the address of an int variable that stores the value of the flag.
+// Each occurrence of the flag, as in -vvv, increments the count; -v=3 sets it and -v=0 clears it.
+func CountLong(name rune, long string, value int, usage string) *int {
+	return CommandLine.CountLong(name, long, value, usage)
+}
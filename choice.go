@@ -0,0 +1,189 @@
+package oldflag
+
+import (
+	"fmt"
+	"strings"
+)
+
+// An Enum is one of the values allowed for an enumerated flag, with a
+// description shown in the usage message.
+type Enum struct {
+	Name        string
+	Description string
+}
+
+// optional interface to indicate flags whose choices are described
+type describedFlag interface {
+	choiceFlag
+	descriptions() []string
+}
+
+// -- enumerated string Value
+type choiceValue struct {
+	p       *string
+	choices []Enum
+}
+
+func newChoiceValue(val string, p *string, choices []Enum) *choiceValue {
+	*p = val
+	return &choiceValue{p, choices}
+}
+
+func (c *choiceValue) Set(s string) error {
+	for _, choice := range c.choices {
+		if s == choice.Name {
+			*c.p = s
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of %s", strings.Join(c.Choices(), ", "))
+}
+
+func (c *choiceValue) Get() interface{} { return *c.p }
+
+func (c *choiceValue) String() string {
+	if c.p == nil {
+		return ""
+	}
+	return *c.p
+}
+
+func (c *choiceValue) Choices() []string {
+	names := make([]string, len(c.choices))
+	for i, choice := range c.choices {
+		names[i] = choice.Name
+	}
+	return names
+}
+
+func (c *choiceValue) descriptions() []string {
+	var descs []string
+	for i, choice := range c.choices {
+		if choice.Description != "" {
+			if descs == nil {
+				descs = make([]string, len(c.choices))
+			}
+			descs[i] = choice.Description
+		}
+	}
+	return descs
+}
+
+// describedChoices returns the choices of flag and their descriptions,
+// which are nil if none of them is described.
+func describedChoices(flag *Flag) (names, descs []string) {
+	names = choices(flag)
+	if fv, ok := flag.Value.(describedFlag); ok {
+		descs = fv.descriptions()
+	}
+	return names, descs
+}
+
+// expandChoice returns the choice of flag that value is a unique prefix of,
+// or value itself if there is none. An empty value abbreviates nothing.
+func expandChoice(flag *Flag, value string) string {
+	if value == "" {
+		return value
+	}
+	match := ""
+	for _, c := range choices(flag) {
+		if c == value {
+			return value
+		}
+		if strings.HasPrefix(c, value) {
+			if match != "" {
+				return value
+			}
+			match = c
+		}
+	}
+	if match == "" {
+		return value
+	}
+	return match
+}
+
+// makeChoices turns names into undescribed Enums.
+func makeChoices(names []string) []Enum {
+	choices := make([]Enum, len(names))
+	for i, name := range names {
+		choices[i].Name = name
+	}
+	return choices
+}
+
+// ChoiceVar defines a string flag with specified name, default value, allowed values, and usage string.
+// The argument p points to a string variable in which to store the value of the flag.
+func (f *FlagSet) ChoiceVar(p *string, name rune, value string, choices []string, usage string) {
+	f.Var(newChoiceValue(value, p, makeChoices(choices)), name, usage)
+}
+
+// ChoiceVar defines a string flag with specified name, default value, allowed values, and usage string.
+// The argument p points to a string variable in which to store the value of the flag.
+func ChoiceVar(p *string, name rune, value string, choices []string, usage string) {
+	CommandLine.Var(newChoiceValue(value, p, makeChoices(choices)), name, usage)
+}
+
+// Choice defines a string flag with specified name, default value, allowed values, and usage string.
+// The return value is the address of a string variable that stores the value of the flag.
+func (f *FlagSet) Choice(name rune, value string, choices []string, usage string) *string {
+	p := new(string)
+	f.ChoiceVar(p, name, value, choices, usage)
+	return p
+}
+
+// Choice defines a string flag with specified name, default value, allowed values, and usage string.
+// The return value is the address of a string variable that stores the value of the flag.
+func Choice(name rune, value string, choices []string, usage string) *string {
+	return CommandLine.Choice(name, value, choices, usage)
+}
+
+// ChoiceVarLong defines a string flag with specified name, long name, default value, allowed values, and usage string.
+// The argument p points to a string variable in which to store the value of the flag.
+func (f *FlagSet) ChoiceVarLong(p *string, name rune, long string, value string, choices []string, usage string) {
+	f.VarLong(newChoiceValue(value, p, makeChoices(choices)), name, long, usage)
+}
+
+// ChoiceVarLong defines a string flag with specified name, long name, default value, allowed values, and usage string.
+// The argument p points to a string variable in which to store the value of the flag.
+func ChoiceVarLong(p *string, name rune, long string, value string, choices []string, usage string) {
+	CommandLine.VarLong(newChoiceValue(value, p, makeChoices(choices)), name, long, usage)
+}
+
+// ChoiceLong defines a string flag with specified name, long name, default value, allowed values, and usage string.
+// The return value is the address of a string variable that stores the value of the flag.
+func (f *FlagSet) ChoiceLong(name rune, long string, value string, choices []string, usage string) *string {
+	p := new(string)
+	f.ChoiceVarLong(p, name, long, value, choices, usage)
+	return p
+}
+
+// ChoiceLong defines a string flag with specified name, long name, default value, allowed values, and usage string.
+// The return value is the address of a string variable that stores the value of the flag.
+func ChoiceLong(name rune, long string, value string, choices []string, usage string) *string {
+	return CommandLine.ChoiceLong(name, long, value, choices, usage)
+}
+
+// EnumVar defines a string flag with specified name, default value, described allowed values, and usage string.
+// The argument p points to a string variable in which to store the value of the flag.
+func (f *FlagSet) EnumVar(p *string, name rune, value string, choices []Enum, usage string) {
+	f.Var(newChoiceValue(value, p, choices), name, usage)
+}
+
+// EnumVar defines a string flag with specified name, default value, described allowed values, and usage string.
+// The argument p points to a string variable in which to store the value of the flag.
+func EnumVar(p *string, name rune, value string, choices []Enum, usage string) {
+	CommandLine.Var(newChoiceValue(value, p, choices), name, usage)
+}
+
+// EnumVarLong defines a string flag with specified name, long name, default value, described allowed values, and usage string.
+// The argument p points to a string variable in which to store the value of the flag.
+func (f *FlagSet) EnumVarLong(p *string, name rune, long string, value string, choices []Enum, usage string) {
+	f.VarLong(newChoiceValue(value, p, choices), name, long, usage)
+}
+
+// EnumVarLong defines a string flag with specified name, long name, default value, described allowed values, and usage string.
+// The argument p points to a string variable in which to store the value of the flag.
+func EnumVarLong(p *string, name rune, long string, value string, choices []Enum, usage string) {
+	CommandLine.VarLong(newChoiceValue(value, p, choices), name, long, usage)
+}
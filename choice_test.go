@@ -0,0 +1,45 @@
+package oldflag
+
+import (
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestChoice(t *testing.T) {
+	tests := []struct {
+		abbrev bool
+		args   []string
+		want   string // the value, or the error
+	}{
+		{false, nil, "fast"},
+		{false, []string{"-m", "safe"}, "safe"},
+		{false, []string{"-ms"}, `invalid value "s" for flag -m: must be one of fast, safe, slow, off`},
+		{true, []string{"-msa"}, "safe"},
+		{true, []string{"--mode=o"}, "off"},
+		{true, []string{"-ms"}, `invalid value "s" for flag -m: must be one of fast, safe, slow, off`},
+	}
+	for _, tt := range tests {
+		f := NewFlagSet("test", ContinueOnError)
+		f.SetOutput(io.Discard)
+		m := f.ChoiceLong('m', "mode", "fast", []string{"fast", "safe", "slow", "off"}, "")
+		f.Lookup('m').Abbrev = tt.abbrev
+		got := fmt.Sprint(f.Parse(tt.args))
+		if got == "<nil>" {
+			got = *m
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) with Abbrev %v: got %s, want %s", tt.args, tt.abbrev, got, tt.want)
+		}
+	}
+
+	// An empty value is not an abbreviation, even of the only choice.
+	f := NewFlagSet("test", ContinueOnError)
+	f.SetOutput(io.Discard)
+	f.Choice('m', "fast", []string{"fast"}, "")
+	f.Lookup('m').Abbrev = true
+	want := `invalid value "" for flag -m: must be one of fast`
+	if err := f.Parse([]string{"-m="}); err == nil || err.Error() != want {
+		t.Errorf("Parse(-m=) with Abbrev: got %v, want %s", err, want)
+	}
+}
@@ -0,0 +1,267 @@
+package oldflag
+
+import (
+	"fmt"
+	"text/tabwriter"
+)
+
+// A Command is a verb of a command-line tool, such as "remote" and "add"
+// in "tool remote add -f x name". Each Command owns a FlagSet and may have
+// child commands, forming a tree whose root stands for the tool itself.
+// Parsing the root routes the arguments down the tree: each command parses
+// its flags up to the first operand, which names the child to continue
+// with. The command the routing ends at keeps the remaining operands in
+// its FlagSet.
+//
+// Flags defined in the Persistent flag set of a command are also accepted
+// by all of its descendants, and share their values with them.
+//
+// A command with children understands the verb "help", unless it has a
+// child of that name: "tool help remote add" prints the usage of the
+// "remote add" command.
+type Command struct {
+	Name  string // name the command is invoked by
+	Short string // one-line description, shown in the parent's usage
+	Long  string // longer description, shown in the command's usage
+
+	// Run is called by Execute for the command the arguments were routed to,
+	// with the operands left after its flags. A command with children may
+	// leave it nil, in which case one of the children must be named.
+	Run func(cmd *Command, args []string) error
+
+	Flags      *FlagSet // flags of the command
+	Persistent *FlagSet // flags of the command and of its descendants
+
+	parent   *Command
+	children []*Command
+}
+
+// NewCommand returns a new command with the specified name, description
+// and Run function, and with empty flag sets. The flag sets use the
+// ContinueOnError error handling policy, and the usage message of the
+// command, which lists its children, its flags, and the persistent flags
+// it inherits.
+func NewCommand(name, short string, run func(cmd *Command, args []string) error) *Command {
+	c := &Command{
+		Name:       name,
+		Short:      short,
+		Run:        run,
+		Flags:      NewFlagSet(name, ContinueOnError),
+		Persistent: NewFlagSet(name, ContinueOnError),
+	}
+	c.Flags.Usage = c.defaultUsage
+	c.Persistent.Usage = c.defaultUsage
+	return c
+}
+
+// AddCommand adds children to the command.
+func (c *Command) AddCommand(children ...*Command) {
+	for _, child := range children {
+		if c.Lookup(child.Name) != nil {
+			panic(fmt.Sprintf("%s command redefined: %s", c.Path(), child.Name))
+		}
+		child.parent = c
+		c.children = append(c.children, child)
+	}
+}
+
+// Parent returns the parent of the command, or nil for the root.
+func (c *Command) Parent() *Command {
+	return c.parent
+}
+
+// Commands returns the children of the command, in the order they were added.
+func (c *Command) Commands() []*Command {
+	return c.children
+}
+
+// Lookup returns the named child of the command, returning nil if none exists.
+func (c *Command) Lookup(name string) *Command {
+	for _, child := range c.children {
+		if child.Name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// Path returns the names of the command and its ancestors, starting with
+// the root, separated by spaces, such as "tool remote add".
+func (c *Command) Path() string {
+	if c.parent == nil {
+		return c.Name
+	}
+	return c.parent.Path() + " " + c.Name
+}
+
+// Args returns the operands left after the command's flags.
+func (c *Command) Args() []string {
+	return c.Flags.Args()
+}
+
+// inherit adds the command's persistent flags, and those of its ancestors,
+// to its flag set. A flag of the command, or of a nearer ancestor, with the
+// same name or long name as an inherited flag shadows it, and the inherited
+// flag is left out. Unless its output has been set, the command also
+// inherits that of its ancestors.
+func (c *Command) inherit() {
+	for cmd := c; cmd != nil && c.Flags.output == nil; cmd = cmd.parent {
+		c.Flags.output = cmd.Flags.output
+	}
+	for cmd := c; cmd != nil; cmd = cmd.parent {
+		for _, flag := range cmd.Persistent.flags {
+			if !c.Flags.hasName(flag) {
+				c.Flags.addFlag(flag)
+			}
+		}
+	}
+}
+
+// Parse routes the argument list, which should not include the name of the
+// tool, down the command tree, parsing the flags of each command on the
+// way. It returns the command the routing ended at, whose FlagSet holds the
+// remaining operands. Errors are handled according to the error handling
+// policy of the FlagSet of the command they occurred in. If the first
+// argument is "__complete", Parse writes completions for the rest to
+// standard output, as by Complete, and then exits with status 0 if the
+// error handling policy of c is ExitOnError, or returns an error wrapping
+// ErrHelp otherwise. Only the first argument is treated so: the word
+// "__complete" later in the list is an ordinary operand.
+func (c *Command) Parse(arguments []string) (*Command, error) {
+	if len(arguments) > 0 && arguments[0] == completeArg {
+		c.inherit()
+		return c, c.completeMain(arguments[1:])
+	}
+	cmd := c
+	var carried map[*Flag]Source
+	base := 0 // index of arguments in the ones given to Parse
+	for {
+		cmd.inherit()
+		// The persistent flags set by the parent keep their values, and
+		// where they came from, so that the environment does not
+		// override them and repeatable flags add to them.
+		cmd.Flags.carried, cmd.Flags.routed, cmd.Flags.base = carried, true, base
+		if len(cmd.children) == 0 {
+			err := cmd.Flags.Parse(arguments)
+			cmd.Flags.carried, cmd.Flags.routed, cmd.Flags.base = nil, false, 0
+			return cmd, err
+		}
+
+		// The first operand names a child, so flags must not be looked
+		// for past it. The required persistent flags may still be given
+		// after it, so the child checks them.
+		ordering := cmd.Flags.Ordering
+		cmd.Flags.Ordering = RequireOrder
+		cmd.Flags.deferred = cmd.persistent
+		err := cmd.Flags.Parse(arguments)
+		cmd.Flags.Ordering = ordering
+		cmd.Flags.carried, cmd.Flags.routed, cmd.Flags.deferred, cmd.Flags.base = nil, false, nil, 0
+		if err != nil {
+			return cmd, err
+		}
+		carried = cmd.Flags.seen
+
+		args := cmd.Flags.Args()
+		if len(args) == 0 {
+			if cmd.Run == nil {
+				return cmd, cmd.Flags.handleError(cmd.Flags.failf("missing command"))
+			}
+			return cmd, cmd.checkRequired()
+		}
+		child := cmd.Lookup(args[0])
+		if child == nil && args[0] == "help" {
+			return cmd, cmd.help(args[1:])
+		}
+		if child == nil {
+			if cmd.Run == nil {
+				return cmd, cmd.Flags.handleError(cmd.Flags.failf("unknown command %q", args[0]))
+			}
+			return cmd, cmd.checkRequired()
+		}
+		base += len(arguments) - len(args) + 1
+		cmd, arguments = child, args[1:]
+	}
+}
+
+// persistent reports whether flag is a persistent flag of the command or of
+// one of its ancestors.
+func (c *Command) persistent(flag *Flag) bool {
+	for cmd := c; cmd != nil; cmd = cmd.parent {
+		if cmd.Persistent.defines(flag) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRequired checks the required flags of a command with children that
+// the routing ended at, including the persistent ones its Parse left out.
+func (c *Command) checkRequired() error {
+	if err := c.Flags.checkRequired(); err != nil {
+		return c.Flags.handleError(err)
+	}
+	return nil
+}
+
+// help prints the usage of the command named by args, relative to c.
+func (c *Command) help(args []string) error {
+	cmd := c
+	for _, name := range args {
+		child := cmd.Lookup(name)
+		if child == nil {
+			return c.Flags.handleError(c.Flags.failf("unknown help topic %q", name))
+		}
+		cmd = child
+	}
+	cmd.inherit()
+	cmd.Flags.usage()
+	return ErrHelp
+}
+
+// Execute parses the argument list as Parse does, and calls the Run
+// function of the command it was routed to with the remaining operands.
+// It is an error for that command to have no Run function. After writing
+// completions, Execute returns nil without running a command.
+func (c *Command) Execute(arguments []string) error {
+	cmd, err := c.Parse(arguments)
+	if err == errCompleted {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if cmd.Run == nil {
+		return cmd.Flags.handleError(cmd.Flags.failf("command %s cannot be run", cmd.Path()))
+	}
+	return cmd.Run(cmd, cmd.Flags.Args())
+}
+
+// defaultUsage is the default function to print the usage message of a command.
+func (c *Command) defaultUsage() {
+	out := c.Flags.Output()
+	if len(c.children) > 0 && c.Run == nil {
+		fmt.Fprintf(out, "Usage: %s [flags] command ...\n", c.Path())
+	} else if len(c.children) > 0 {
+		fmt.Fprintf(out, "Usage: %s [flags] [command ...]\n", c.Path())
+	} else {
+		fmt.Fprintf(out, "Usage: %s [flags] ...\n", c.Path())
+	}
+	if c.Long != "" {
+		fmt.Fprintf(out, "\n%s\n", c.Long)
+	} else if c.Short != "" {
+		fmt.Fprintf(out, "\n%s\n", c.Short)
+	}
+	if len(c.children) > 0 {
+		fmt.Fprintf(out, "\nCommands:\n")
+		w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+		for _, child := range c.children {
+			fmt.Fprintf(w, "  %s\t%s\n", child.Name, child.Short)
+		}
+		w.Flush()
+		fmt.Fprintf(out, "\nRun '%s help command' for the usage of a command.\n", c.Path())
+	}
+	if len(c.Flags.flags) > 0 {
+		fmt.Fprintf(out, "\nFlags:\n")
+		c.Flags.PrintDefaults()
+	}
+}
@@ -0,0 +1,182 @@
+package oldflag
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// newTestTree returns a tool with a "remote add" command, and the values
+// of the persistent flags of the tool.
+func newTestTree() (tool *Command, verbose *int, include *[]string) {
+	tool = NewCommand("tool", "", nil)
+	tool.Flags.SetOutput(io.Discard)
+	verbose = tool.Persistent.Int('v', 0, "verbosity `level`")
+	tool.Persistent.Lookup('v').Env = "TEST_VERBOSE"
+	include = tool.Persistent.StringSlice('I', nil, "search `dir`")
+	remote := NewCommand("remote", "", nil)
+	remote.Flags.Bool('f', false, "force")
+	add := NewCommand("add", "", func(cmd *Command, args []string) error { return nil })
+	remote.AddCommand(add)
+	tool.AddCommand(remote)
+	return tool, verbose, include
+}
+
+func TestCommandParse(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"remote", "add", "x"}, `tool remote add: v=0 I=[] args=["x"]`},
+		{[]string{"-v", "3", "remote", "-f", "add", "x"}, `tool remote add: v=3 I=[] args=["x"]`},
+		{[]string{"remote", "add", "-v2", "x"}, `tool remote add: v=2 I=[] args=["x"]`},
+		{[]string{"-I", "a", "remote", "-I", "b", "add", "-I", "c"}, `tool remote add: v=0 I=[a b c] args=[]`},
+		{[]string{"remote"}, `missing command`},
+		{[]string{"remote", "rm"}, `unknown command "rm"`},
+	}
+	for _, tt := range tests {
+		tool, v, include := newTestTree()
+		cmd, err := tool.Parse(tt.args)
+		got := ""
+		if err != nil {
+			got = err.Error()
+		} else {
+			got = fmt.Sprintf("%s: v=%d I=%v args=%q", cmd.Path(), *v, *include, cmd.Args())
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q):\n got %s\nwant %s", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestCommandPersistent(t *testing.T) {
+	// A required persistent flag may be given before the command.
+	for _, args := range [][]string{{"-v", "3", "remote", "add"}, {"remote", "add", "-v", "3"}} {
+		tool, _, _ := newTestTree()
+		tool.Persistent.Lookup('v').Required = true
+		if _, err := tool.Parse(args); err != nil {
+			t.Errorf("Parse(%q) with -v required: %v", args, err)
+		}
+	}
+	tool, _, _ := newTestTree()
+	tool.Persistent.Lookup('v').Required = true
+	want := "missing required flag: -v"
+	if _, err := tool.Parse([]string{"remote", "add"}); err == nil || err.Error() != want {
+		t.Errorf("required flag not given: got %v, want %s", err, want)
+	}
+
+	// The environment does not override a persistent flag given before
+	// the command.
+	t.Setenv("TEST_VERBOSE", "1")
+	tool, v, _ := newTestTree()
+	if _, err := tool.Parse([]string{"-v", "3", "remote", "add"}); err != nil {
+		t.Fatal(err)
+	}
+	if *v != 3 {
+		t.Errorf("-v 3 with $TEST_VERBOSE=1: v = %d, want 3", *v)
+	}
+	if o := tool.Persistent.Lookup('v').Origin.String(); o != "argument 0" {
+		t.Errorf("origin of -v = %s, want argument 0", o)
+	}
+	// Origins refer to the arguments given to the root.
+	if _, err := tool.Parse([]string{"remote", "-f", "add", "-v2", "x"}); err != nil {
+		t.Fatal(err)
+	}
+	if o := tool.Persistent.Lookup('v').Origin.String(); o != "argument 3" {
+		t.Errorf("origin of -v = %s, want argument 3", o)
+	}
+	if o := tool.Lookup("remote").Flags.Lookup('f').Origin.String(); o != "argument 1" {
+		t.Errorf("origin of -f = %s, want argument 1", o)
+	}
+
+	// Each command does not apply the environment again to a persistent
+	// flag its parent set from it.
+	t.Setenv("TEST_INCLUDE", "e")
+	tool, _, include := newTestTree()
+	tool.Persistent.Lookup('I').Env = "TEST_INCLUDE"
+	if _, err := tool.Parse([]string{"remote", "add", "x"}); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(*include) != "[e]" {
+		t.Errorf("$TEST_INCLUDE=e: I = %v, want [e]", *include)
+	}
+	if _, err := tool.Parse([]string{"remote", "-I", "a", "add", "x"}); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(*include) != "[a]" {
+		t.Errorf("-I a with $TEST_INCLUDE=e: I = %v, want [a]", *include)
+	}
+}
+
+func TestCommandHelp(t *testing.T) {
+	tests := []struct {
+		args  []string
+		usage string // first line of the usage printed, if any
+		err   string
+	}{
+		{[]string{"help", "remote", "add"}, "Usage: tool remote add [flags] ...", ErrHelp.Error()},
+		{[]string{"remote", "help", "add"}, "Usage: tool remote add [flags] ...", ErrHelp.Error()},
+		{[]string{"help"}, "Usage: tool [flags] command ...", ErrHelp.Error()},
+		{[]string{"remote", "add", "--help"}, "Usage: tool remote add [flags] ...", ErrHelp.Error()},
+		{[]string{"help", "nope"}, "", `unknown help topic "nope"`},
+		{[]string{"help", "remote", "nope"}, "", `unknown help topic "nope"`},
+	}
+	for _, tt := range tests {
+		tool, _, _ := newTestTree()
+		var buf bytes.Buffer
+		tool.Flags.SetOutput(&buf)
+		_, err := tool.Parse(tt.args)
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("Parse(%q): got error %v, want %s", tt.args, err, tt.err)
+		}
+		if tt.err == ErrHelp.Error() && !errors.Is(err, ErrHelp) {
+			t.Errorf("Parse(%q): got error %v, want ErrHelp", tt.args, err)
+		}
+		if tt.usage == "" {
+			continue
+		}
+		if usage, _, _ := strings.Cut(buf.String(), "\n"); usage != tt.usage {
+			t.Errorf("Parse(%q): usage begins with %q, want %q", tt.args, usage, tt.usage)
+		}
+	}
+}
+
+func TestCommandShadow(t *testing.T) {
+	// A flag of a command shadows a persistent flag of the same name.
+	tool, v, _ := newTestTree()
+	add := tool.Lookup("remote").Lookup("add")
+	version := add.Flags.Bool('v', false, "print the version")
+	for i := 0; i < 2; i++ {
+		if _, err := tool.Parse([]string{"-v", "3", "remote", "add", "-v"}); err != nil {
+			t.Fatal(err)
+		}
+		if *v != 3 || !*version {
+			t.Errorf("-v 3 remote add -v: got persistent %d and own %v, want 3 and true", *v, *version)
+		}
+	}
+	if err := tool.Complete(io.Discard, []string{"remote", "add", "-"}); err != nil {
+		t.Errorf("Complete: %v", err)
+	}
+}
+
+func TestCommandExecute(t *testing.T) {
+	tool, _, _ := newTestTree()
+	var got []string
+	tool.Lookup("remote").Lookup("add").Run = func(cmd *Command, args []string) error {
+		got = args
+		return nil
+	}
+	if err := tool.Execute([]string{"remote", "add", "x"}); err != nil || fmt.Sprint(got) != "[x]" {
+		t.Errorf("Execute: got %v, %v; want [x], nil", got, err)
+	}
+
+	leaf := NewCommand("leaf", "", nil)
+	leaf.Flags.SetOutput(io.Discard)
+	want := "command leaf cannot be run"
+	if err := leaf.Execute(nil); err == nil || err.Error() != want {
+		t.Errorf("Execute of a command without Run: got %v, want %s", err, want)
+	}
+}
@@ -0,0 +1,385 @@
+package oldflag
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// completeArg is the hidden first argument that makes Parse write
+// completions instead of parsing.
+const completeArg = "__complete"
+
+// A Directive tells the shell what to do with a list of completions.
+type Directive int
+
+// These flags may be combined in a Directive.
+const (
+	NoFileFallback Directive = 1 << iota // Do not complete file names if there are no completions.
+	NoSpace                              // Do not add a space after a completion.
+)
+
+// A Completer offers completions, at run time, for the value of a flag or
+// for an operand. Complete returns the candidates that start with prefix,
+// the part of the value typed so far, and a Directive for the shell.
+type Completer interface {
+	Complete(prefix string) ([]string, Directive)
+}
+
+// The CompleterFunc type is an adapter to allow the use of ordinary
+// functions as Completers.
+type CompleterFunc func(prefix string) ([]string, Directive)
+
+// Complete calls fn(prefix).
+func (fn CompleterFunc) Complete(prefix string) ([]string, Directive) {
+	return fn(prefix)
+}
+
+// Complete writes to w the completions of the last of args, the word being
+// completed, which may be empty. The words before it are parsed as by Parse,
+// quietly and without regard to errors, so that Completers can look at the
+// flags given so far, and so that the word is known to be a flag, the value
+// of a flag, or an operand. Values are completed by the flag's Completer,
+// by its Value if that implements Completer, or from its choices; operands by
+// OperandCompleter. Otherwise there are no completions, and the shell
+// completes file names.
+//
+// Completions are written one per line, followed by a line holding a colon
+// and the Directive as a decimal number, such as
+//	fast
+//	safe
+//	:1
+// Programs need not call Complete themselves: Parse calls it when its first
+// argument is "__complete", which is how the scripts written by
+// WriteBashCompletion and the like call it when some flag has a Completer.
+func (f *FlagSet) Complete(w io.Writer, args []string) error {
+	candidates, dir, _ := f.completions(args)
+	return writeCompletions(w, candidates, dir)
+}
+
+// writeCompletions writes completions in the line protocol of Complete.
+func writeCompletions(w io.Writer, candidates []string, dir Directive) error {
+	var b strings.Builder
+	for _, c := range candidates {
+		fmt.Fprintf(&b, "%s\n", c)
+	}
+	fmt.Fprintf(&b, ":%d\n", dir)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// completions returns the completions of the last of args, and reports
+// whether it is an operand. The operands that precede it are left in f.
+func (f *FlagSet) completions(args []string) ([]string, Directive, bool) {
+	cur := ""
+	if len(args) > 0 {
+		cur, args = args[len(args)-1], args[:len(args)-1]
+	}
+
+	output, usage, version, errorHandling := f.output, f.Usage, f.Version, f.errorHandling
+	f.output, f.Usage, f.Version, f.errorHandling = io.Discard, func() {}, nil, ContinueOnError
+	f.completing = true
+	err := f.Parse(args)
+	f.output, f.Usage, f.Version, f.errorHandling = output, usage, version, errorHandling
+	f.completing = false
+
+	if err != nil {
+		if f.needArg != nil {
+			candidates, dir := completeValue(f.needArg, cur)
+			return candidates, dir, false
+		}
+		return nil, NoFileFallback, false
+	}
+	flagsDone := f.terminated || f.ordering() == RequireOrder && len(f.args) > 0
+	if flagsDone || !strings.HasPrefix(cur, "-") {
+		if f.OperandCompleter != nil {
+			candidates, dir := f.OperandCompleter.Complete(cur)
+			return candidates, dir, true
+		}
+		return nil, 0, true
+	}
+	candidates, dir := f.completeFlag(cur)
+	return candidates, dir, false
+}
+
+// completeFlag returns the completions of cur, a word starting with -.
+func (f *FlagSet) completeFlag(cur string) ([]string, Directive) {
+	var candidates []string
+	if strings.HasPrefix(cur, "--") {
+		if i := strings.IndexByte(cur, '='); i >= 0 {
+			flag := f.formalLong[cur[2:i]]
+			if flag == nil || flag.kind() == kindBool {
+				return nil, NoFileFallback
+			}
+			candidates, dir := completeValue(flag, cur[i+1:])
+			return prefixed(cur[:i+1], candidates), dir
+		}
+		for _, flag := range sortFlags(f.flags) {
+			if flag.Long != "" && strings.HasPrefix("--"+flag.Long, cur) {
+				candidates = append(candidates, "--"+flag.Long)
+			}
+		}
+		return candidates, NoFileFallback
+	}
+
+	// Walk the cluster as parseOne does; a flag taking an argument takes
+	// the rest of it.
+	for i, r := range cur[1:] {
+		flag := f.formal[r]
+		if flag == nil {
+			return nil, NoFileFallback
+		}
+		if flag.kind() != kindBool {
+			j := 1 + i + utf8.RuneLen(r)
+			if strings.HasPrefix(cur[j:], "=") {
+				j++
+			}
+			candidates, dir := completeValue(flag, cur[j:])
+			return prefixed(cur[:j], candidates), dir
+		}
+	}
+	if cur == "-" {
+		for _, flag := range sortFlags(f.flags) {
+			if flag.Long != "" {
+				candidates = append(candidates, "--"+flag.Long)
+			}
+		}
+	} else {
+		candidates = append(candidates, cur)
+	}
+	for _, flag := range sortFlags(f.flags) {
+		if flag.Name != 0 {
+			candidates = append(candidates, cur+string(flag.Name))
+		}
+	}
+	return candidates, NoFileFallback
+}
+
+// completeValue returns the completions of prefix as the value of flag.
+func completeValue(flag *Flag, prefix string) ([]string, Directive) {
+	if flag.Completer != nil {
+		return flag.Completer.Complete(prefix)
+	}
+	if c, ok := flag.Value.(Completer); ok {
+		return c.Complete(prefix)
+	}
+	if c := choices(flag); c != nil {
+		var candidates []string
+		for _, v := range c {
+			if strings.HasPrefix(v, prefix) {
+				candidates = append(candidates, v)
+			}
+		}
+		return candidates, NoFileFallback
+	}
+	return nil, 0
+}
+
+// prefixed puts prefix in front of each of candidates.
+func prefixed(prefix string, candidates []string) []string {
+	for i := range candidates {
+		candidates[i] = prefix + candidates[i]
+	}
+	return candidates
+}
+
+// dynamic reports whether completing f needs to run the program, because
+// some flag or the operands have a Completer.
+func (f *FlagSet) dynamic() bool {
+	if f.OperandCompleter != nil {
+		return true
+	}
+	for _, flag := range f.flags {
+		if _, ok := flag.Value.(Completer); ok || flag.Completer != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Complete writes to w the completions of the last of args, which are
+// routed down the command tree as by Parse. The word being completed is
+// either a flag, the value of a flag or an operand of the command the words
+// before it lead to, as for FlagSet.Complete, or the name of one of its
+// children. Programs need not call Complete themselves: Parse calls it when
+// its first argument is "__complete".
+func (c *Command) Complete(w io.Writer, args []string) error {
+	if len(args) == 0 {
+		args = []string{""}
+	}
+	cmd := c
+	for {
+		cmd.inherit()
+		if len(cmd.children) == 0 {
+			return cmd.Flags.Complete(w, args)
+		}
+
+		ordering := cmd.Flags.Ordering
+		cmd.Flags.Ordering = RequireOrder
+		candidates, dir, operand := cmd.Flags.completions(args)
+		cmd.Flags.Ordering = ordering
+		if !operand {
+			return writeCompletions(w, candidates, dir)
+		}
+
+		rest := cmd.Flags.Args()
+		if len(rest) == 0 {
+			cur := args[len(args)-1]
+			candidates = nil
+			for _, child := range cmd.children {
+				if strings.HasPrefix(child.Name, cur) {
+					candidates = append(candidates, child.Name)
+				}
+			}
+			if cmd.Lookup("help") == nil && strings.HasPrefix("help", cur) {
+				candidates = append(candidates, "help")
+			}
+			return writeCompletions(w, candidates, NoFileFallback)
+		}
+		child := cmd.Lookup(rest[0])
+		if child == nil {
+			return writeCompletions(w, nil, NoFileFallback)
+		}
+		cmd, args = child, append(rest[1:len(rest):len(rest)], args[len(args)-1])
+	}
+}
+
+// dynamic reports whether completing the command tree rooted at c needs to
+// run the program.
+func (c *Command) dynamic() bool {
+	c.inherit()
+	if c.Flags.dynamic() {
+		return true
+	}
+	for _, child := range c.children {
+		if child.dynamic() {
+			return true
+		}
+	}
+	return false
+}
+
+// errCompleted is returned by Parse after writing completions, when the
+// error handling policy does not exit.
+var errCompleted = fmt.Errorf("%w: completions written", ErrHelp)
+
+// completed ends a Parse that has written completions, which is a success:
+// it exits with status 0 if the error handling policy is ExitOnError, and
+// returns errCompleted otherwise.
+func (f *FlagSet) completed() error {
+	if f.errorHandling == ExitOnError {
+		os.Exit(0)
+	}
+	return errCompleted
+}
+
+// completeMain handles the hidden completion argument for Command.Parse.
+func (c *Command) completeMain(arguments []string) error {
+	c.Complete(os.Stdout, arguments)
+	return c.Flags.completed()
+}
+
+// writeDynamicCompletion writes to w a completion script for the shell
+// that asks the program prog for completions at run time.
+func writeDynamicCompletion(w io.Writer, script, prog string) error {
+	_, err := fmt.Fprintf(w, script, "_"+shellName(prog), prog)
+	return err
+}
+
+// bashDynamic is a bash completion script that asks the program for
+// completions. Its arguments are the name of the completion function and
+// the name of the program.
+const bashDynamic = `# bash completion for %[2]s
+
+%[1]s() {
+	local -a words=() lines=()
+	local i w strip line
+	# bash splits --long=value into three words; join them again.
+	for ((i = 1; i <= COMP_CWORD; i++)); do
+		w=${COMP_WORDS[i]}
+		if ((i > 1)) && [[ $w == = || ${COMP_WORDS[i-1]} == = ]]; then
+			words[${#words[@]}-1]+=$w
+		else
+			words+=("$w")
+		fi
+	done
+	# The part of the joined word before the one bash completes.
+	w=${COMP_WORDS[COMP_CWORD]}
+	[[ $w == = ]] && w=
+	strip=${words[${#words[@]}-1]}
+	strip=${strip%%"$w"}
+
+	local IFS=$'\n'
+	lines=($("${COMP_WORDS[0]}" __complete "${words[@]}" 2>/dev/null))
+	local dir=${lines[${#lines[@]}-1]#:}
+	unset 'lines[${#lines[@]}-1]'
+	COMPREPLY=()
+	for line in "${lines[@]}"; do
+		COMPREPLY+=("${line#"$strip"}")
+	done
+	if ((dir & 2)); then
+		compopt -o nospace
+	fi
+	if ((${#COMPREPLY[@]} == 0 && !(dir & 1))); then
+		COMPREPLY=($(compgen -f -- "$w"))
+	fi
+}
+
+complete -F %[1]s %[2]s
+`
+
+// zshDynamic is a zsh completion function that asks the program for
+// completions. Its arguments are the name of the completion function and
+// the name of the program.
+const zshDynamic = `#compdef %[2]s
+
+%[1]s() {
+	local -a lines candidates
+	local dir
+	lines=("${(@f)$("$words[1]" __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+	dir=${lines[-1]#:}
+	candidates=("${(@)lines[1,-2]}")
+	if [[ $PREFIX == -*=* ]]; then
+		compset -P '*='
+		candidates=("${(@)candidates#*=}")
+	fi
+	if (( ${#candidates} )); then
+		if (( dir & 2 )); then
+			compadd -S '' -- "${candidates[@]}"
+		else
+			compadd -- "${candidates[@]}"
+		fi
+	elif (( !(dir & 1) )); then
+		_files
+	fi
+}
+
+if [[ $funcstack[1] == %[1]s ]]; then
+	%[1]s "$@"
+else
+	compdef %[1]s %[2]s
+fi
+`
+
+// fishDynamic is a fish completion script that asks the program for
+// completions. Its arguments are the name of the completion function and
+// the name of the program.
+const fishDynamic = `# fish completion for %[2]s
+
+function %[1]s
+	set -l words (commandline -opc)
+	set -l cur (commandline -ct)
+	set -l lines ($words[1] __complete $words[2..-1] $cur 2>/dev/null)
+	set -l dir (string replace ':' '' -- $lines[-1])
+	set -e lines[-1]
+	if test (count $lines) -gt 0
+		printf '%%s\n' $lines
+	else if test (math "$dir %% 2") -eq 0
+		__fish_complete_path $cur
+	end
+end
+
+complete -c %[2]s -f -a '(%[1]s)'
+`
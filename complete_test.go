@@ -0,0 +1,76 @@
+package oldflag
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestComplete(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-a"}, "-a -aa -am :1"},
+		{[]string{"-am"}, "-amfast -amsafe :1"},
+		{[]string{"-am=f"}, "-am=fast :1"},
+		{[]string{"--m"}, "--mode :1"},
+		{[]string{"--mode=s"}, "--mode=safe :1"},
+		{[]string{"-m", ""}, "fast safe :1"},
+		{[]string{"--mode", "s"}, "safe :1"},
+		{[]string{"-z", ""}, ":1"},
+		{[]string{"x"}, ":0"},
+	}
+	for _, tt := range tests {
+		f := NewFlagSet("tool", ContinueOnError)
+		f.Bool('a', false, "all")
+		f.ChoiceLong('m', "mode", "fast", []string{"fast", "safe"}, "the `mode`")
+		var b strings.Builder
+		if err := f.Complete(&b, tt.args); err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Join(strings.Fields(b.String()), " "); got != tt.want {
+			t.Errorf("Complete(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestCommandComplete(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{""}, "remote help :1"},
+		{[]string{"re"}, "remote :1"},
+		{[]string{"-v", "3", "remote", ""}, "add help :1"},
+		{[]string{"remote", "add", "-"}, "-I -v :1"},
+		{[]string{"remote", "nope", ""}, ":1"},
+	}
+	for _, tt := range tests {
+		tool, _, _ := newTestTree()
+		var b strings.Builder
+		if err := tool.Complete(&b, tt.args); err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Join(strings.Fields(b.String()), " "); got != tt.want {
+			t.Errorf("Complete(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+
+	// Only the first argument asks for completions.
+	tool, _, _ := newTestTree()
+	cmd, err := tool.Parse([]string{"remote", "add", "__complete"})
+	if err != nil || fmt.Sprintf("%q", cmd.Args()) != `["__complete"]` {
+		t.Errorf("Parse(remote add __complete): got %q, %v", cmd.Args(), err)
+	}
+	stdout := os.Stdout
+	defer func() { os.Stdout = stdout }()
+	os.Stdout, err = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tool.Execute([]string{"__complete", "re"}); err != nil {
+		t.Errorf("Execute(__complete re): %v", err)
+	}
+}
@@ -0,0 +1,237 @@
+package oldflag
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// optional interface to indicate flags whose value is one of a fixed set
+// of choices, which shell completion offers
+type choiceFlag interface {
+	Value
+	Choices() []string
+}
+
+// flagKind tells what a flag takes after it on the command line.
+type flagKind int
+
+const (
+	kindArg      flagKind = iota // a required argument
+	kindBool                     // no argument
+	kindOptional                 // an optional, attached argument
+)
+
+// kind returns what flag takes after it on the command line.
+func (flag *Flag) kind() flagKind {
+	if flag.Optional {
+		return kindOptional
+	}
+	if fv, ok := flag.Value.(boolFlag); ok && fv.IsBoolFlag() {
+		return kindBool
+	}
+	return kindArg
+}
+
+// choices returns the values offered for flag by shell completion, or nil
+// if any file name may be offered.
+func choices(flag *Flag) []string {
+	if fv, ok := flag.Value.(choiceFlag); ok {
+		return fv.Choices()
+	}
+	return nil
+}
+
+// shellQuote quotes s for the POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// shellName turns the name of a program into part of a shell function name.
+func shellName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// programName returns the name a flag set is invoked by, or "" if it has none.
+func (f *FlagSet) programName() string {
+	if f.name == "" {
+		return ""
+	}
+	return filepath.Base(f.name)
+}
+
+// WriteBashCompletion writes to w a bash completion script for the flags of
+// the set, which must be named after the program they belong to. The script
+// completes flags after -, including further flags in a cluster such as -ab,
+// and long names after --. After a flag that takes an argument, whether
+// attached or in the next word, it completes the value: one of the choices
+// if the flag's Value has a Choices() []string method, or a file name
+// otherwise. Elsewhere it completes file names. If some flag or the
+// operands have a Completer, the script instead asks the program for
+// completions at run time, by running it with the hidden argument
+// "__complete" (see Complete).
+//
+// A program would typically write the script when run with a hidden flag,
+// to be installed as /etc/bash_completion.d/program or sourced from
+// ~/.bashrc.
+func (f *FlagSet) WriteBashCompletion(w io.Writer) error {
+	prog := f.programName()
+	if prog == "" {
+		return fmt.Errorf("flag set has no name to complete")
+	}
+	if f.dynamic() {
+		return writeDynamicCompletion(w, bashDynamic, prog)
+	}
+	fn := "_" + shellName(prog)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# bash completion for %s\n\n", prog)
+
+	// fn_kind prints what the flag named $1, a rune or --long, takes.
+	fmt.Fprintf(&b, "%s_kind() {\n\tcase \"$1\" in\n", fn)
+	kinds := [...]string{kindArg: "arg", kindBool: "bool", kindOptional: "optional"}
+	for _, flag := range sortFlags(f.flags) {
+		fmt.Fprintf(&b, "\t%s) echo %s ;;\n", bashPattern(flag), kinds[flag.kind()])
+	}
+	fmt.Fprintf(&b, "\tesac\n}\n\n")
+
+	// fn_values prints the values of the flag named $1 that start with $2.
+	fmt.Fprintf(&b, "%s_values() {\n\tcase \"$1\" in\n", fn)
+	for _, flag := range sortFlags(f.flags) {
+		if c := choices(flag); c != nil {
+			fmt.Fprintf(&b, "\t%s) compgen -W %s -- \"$2\" ;;\n", bashPattern(flag), shellQuote(strings.Join(c, " ")))
+		}
+	}
+	fmt.Fprintf(&b, "\t*) compgen -f -- \"$2\" ;;\n\tesac\n}\n\n")
+
+	var runes, longs []string
+	for _, flag := range sortFlags(f.flags) {
+		if flag.Name != 0 {
+			runes = append(runes, string(flag.Name))
+		}
+		if flag.Long != "" {
+			longs = append(longs, "--"+flag.Long)
+		}
+	}
+	sort.Strings(longs)
+
+	fmt.Fprintf(&b, bashCompletion, fn, shellQuote(strings.Join(runes, " ")), shellQuote(strings.Join(longs, " ")), prog)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteBashCompletion writes to w a bash completion script for the
+// command-line flags. See FlagSet.WriteBashCompletion for details.
+func WriteBashCompletion(w io.Writer) error {
+	return CommandLine.WriteBashCompletion(w)
+}
+
+// WriteBashCompletion writes to w a bash completion script for the command
+// tree rooted at c, named after the root. The script asks the program for
+// completions at run time, by running it with the hidden argument
+// "__complete", which Parse handles (see Complete).
+func (c *Command) WriteBashCompletion(w io.Writer) error {
+	return writeDynamicCompletion(w, bashDynamic, c.Name)
+}
+
+// bashPattern returns a bash case pattern matching the names of flag.
+func bashPattern(flag *Flag) string {
+	var names []string
+	if flag.Name != 0 {
+		names = append(names, shellQuote(string(flag.Name)))
+	}
+	if flag.Long != "" {
+		names = append(names, shellQuote("--"+flag.Long))
+	}
+	return strings.Join(names, "|")
+}
+
+// bashCompletion is the part of the bash completion script that does not
+// depend on the flags. Its arguments are the name of the completion
+// function, the rune names of the flags, their long names, and the name of
+// the program.
+const bashCompletion = `%[1]s() {
+	local cur=${COMP_WORDS[COMP_CWORD]} prev=${COMP_WORDS[COMP_CWORD-1]}
+	local runes=%[2]s longs=%[3]s
+	local i c
+	COMPREPLY=()
+
+	# bash splits --long=value and -c=value into three words; complete
+	# the value of the flag before the =.
+	if [[ $cur == = || $prev == = ]]; then
+		local w=$prev
+		if [[ $cur == = ]]; then
+			cur=
+		else
+			w=${COMP_WORDS[COMP_CWORD-2]}
+		fi
+		if [[ $w != --* ]]; then
+			w=${w: -1}
+		fi
+		COMPREPLY=($(%[1]s_values "$w" "$cur"))
+		return
+	fi
+
+	# The value of a flag ending the previous word.
+	if [[ $prev == --?* ]]; then
+		if [[ $(%[1]s_kind "$prev") == arg ]]; then
+			COMPREPLY=($(%[1]s_values "$prev" "$cur"))
+			return
+		fi
+	elif [[ $prev == -?* ]]; then
+		for ((i = 1; i < ${#prev}; i++)); do
+			c=${prev:i:1}
+			case $(%[1]s_kind "$c") in
+			bool) ;;
+			arg)
+				if ((i == ${#prev} - 1)); then
+					COMPREPLY=($(%[1]s_values "$c" "$cur"))
+					return
+				fi
+				break ;;
+			*) break ;;
+			esac
+		done
+	fi
+
+	case $cur in
+	--*)
+		COMPREPLY=($(compgen -W "$longs" -- "$cur"))
+		;;
+	-*)
+		# Walk the cluster; a flag taking an argument takes the rest of it.
+		for ((i = 1; i < ${#cur}; i++)); do
+			c=${cur:i:1}
+			case $(%[1]s_kind "$c") in
+			bool) ;;
+			arg|optional)
+				local v
+				for v in $(%[1]s_values "$c" "${cur:i+1}"); do
+					COMPREPLY+=("${cur:0:i+1}$v")
+				done
+				return ;;
+			*) return ;;
+			esac
+		done
+		for c in $runes; do
+			COMPREPLY+=("$cur$c")
+		done
+		if [[ $cur == - ]]; then
+			COMPREPLY+=($longs)
+		fi
+		;;
+	*)
+		COMPREPLY=($(compgen -f -- "$cur"))
+		;;
+	esac
+}
+
+complete -F %[1]s %[4]s
+`
@@ -0,0 +1,182 @@
+package oldflag
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteFishCompletion writes to w fish completions for the flags of the set,
+// which must be named after the program they belong to. Each flag is
+// described by its usage message, and is marked as taking an argument or
+// not; fish has no notion of optional arguments, so a flag whose argument is
+// optional is completed as a boolean flag. A flag is not offered once a flag
+// it may not be given with, such as one declared MutuallyExclusive with it,
+// is on the command line. Arguments are completed as by
+// WriteBashCompletion, which also tells when the completions ask the program
+// for completions.
+//
+// The completions are meant to be installed as program.fish in a directory
+// of $fish_complete_path.
+func (f *FlagSet) WriteFishCompletion(w io.Writer) error {
+	prog := f.programName()
+	if prog == "" {
+		return fmt.Errorf("flag set has no name to complete")
+	}
+	if f.dynamic() {
+		return writeDynamicCompletion(w, fishDynamic, prog)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "# fish completion for %s\n\n", prog)
+	writeFishFlags(&b, prog, "", f)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteFishCompletion writes to w fish completions for the command-line
+// flags. See FlagSet.WriteFishCompletion for details.
+func WriteFishCompletion(w io.Writer) error {
+	return CommandLine.WriteFishCompletion(w)
+}
+
+// WriteFishCompletion writes to w fish completions for the command tree
+// rooted at c, named after the root. Each command completes its flags,
+// including the persistent flags it inherits, as FlagSet.WriteFishCompletion
+// does, and the names of its children. If any command has a Completer, the
+// completions instead ask the program for completions at run time.
+func (c *Command) WriteFishCompletion(w io.Writer) error {
+	if c.dynamic() {
+		return writeDynamicCompletion(w, fishDynamic, c.Name)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "# fish completion for %s\n", c.Name)
+	c.writeFish(&b, nil)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeFish writes the fish completions for c and its descendants. path
+// holds the names of the commands from the child of the root down to c.
+func (c *Command) writeFish(b *strings.Builder, path []string) {
+	c.inherit()
+
+	// The command line is at c once all of path has been seen. Only the
+	// flags of the root are accepted before the first command.
+	var conds []string
+	for _, name := range path {
+		conds = append(conds, "__fish_seen_subcommand_from "+fishQuote(name))
+	}
+	cond := strings.Join(conds, "; and ")
+	if cond == "" {
+		cond = "__fish_use_subcommand"
+	}
+
+	root := c
+	for root.parent != nil {
+		root = root.parent
+	}
+	fmt.Fprintf(b, "\n")
+	if len(c.children) == 0 {
+		writeFishFlags(b, root.Name, cond, c.Flags)
+		return
+	}
+
+	// The flags and the children of c are offered until one of the
+	// children has been seen.
+	var names []string
+	for _, child := range c.children {
+		names = append(names, fishQuote(child.Name))
+	}
+	childCond := "__fish_use_subcommand"
+	if len(path) > 0 {
+		childCond = cond + "; and not __fish_seen_subcommand_from " + strings.Join(names, " ")
+	}
+	writeFishFlags(b, root.Name, childCond, c.Flags)
+	for _, child := range c.children {
+		fmt.Fprintf(b, "complete -c %s -f -n %s -a %s -d %s\n",
+			fishQuote(root.Name), fishQuote(childCond), fishQuote(child.Name), fishQuote(child.Short))
+	}
+	for _, child := range c.children {
+		child.writeFish(b, append(path[:len(path):len(path)], child.Name))
+	}
+}
+
+// writeFishFlags writes a complete command for each flag of f, for the
+// program prog, applying under the condition cond if it is not empty.
+func writeFishFlags(b *strings.Builder, prog, cond string, f *FlagSet) {
+	for _, flag := range sortFlags(f.flags) {
+		_, usage := UnquoteUsage(flag)
+		usage = strings.SplitN(usage, "\n", 2)[0]
+
+		flagCond := cond
+		if excluded := fishContainsOpt(f.exclusions(flag)); excluded != "" {
+			if flagCond != "" {
+				flagCond += "; and "
+			}
+			flagCond += "not " + excluded
+		}
+
+		fmt.Fprintf(b, "complete -c %s", fishQuote(prog))
+		if flagCond != "" {
+			fmt.Fprintf(b, " -n %s", fishQuote(flagCond))
+		}
+		if flag.Name != 0 {
+			fmt.Fprintf(b, " -s %s", fishQuote(string(flag.Name)))
+		}
+		if flag.Long != "" {
+			fmt.Fprintf(b, " -l %s", fishQuote(flag.Long))
+		}
+		if flag.kind() == kindArg {
+			if c, descs := describedChoices(flag); c != nil {
+				quoted := make([]string, len(c))
+				for i := range c {
+					if descs != nil && descs[i] != "" {
+						quoted[i] = fishQuote(c[i] + "\t" + descs[i])
+					} else {
+						quoted[i] = fishQuote(c[i])
+					}
+				}
+				fmt.Fprintf(b, " -x -a %s", fishQuote(strings.Join(quoted, " ")))
+			} else {
+				fmt.Fprintf(b, " -r -F")
+			}
+		}
+		if usage != "" {
+			fmt.Fprintf(b, " -d %s", fishQuote(usage))
+		}
+		fmt.Fprintf(b, "\n")
+	}
+}
+
+// fishContainsOpt returns a fish condition true when any of flags is on
+// the command line, or "" if there are no flags.
+func fishContainsOpt(flags []*Flag) string {
+	if len(flags) == 0 {
+		return ""
+	}
+	var opts []string
+	for _, flag := range flags {
+		if flag.Name != 0 {
+			opts = append(opts, "-s "+fishQuote(string(flag.Name)))
+		}
+		if flag.Long != "" {
+			opts = append(opts, fishQuote(flag.Long))
+		}
+	}
+	return "__fish_contains_opt " + strings.Join(opts, " ")
+}
+
+// fishQuote quotes s for fish, unless it is a plain word that needs no quoting.
+func fishQuote(s string) string {
+	plain := s != ""
+	for _, r := range s {
+		if !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || strings.ContainsRune("-_.,/+", r)) {
+			plain = false
+			break
+		}
+	}
+	if plain {
+		return s
+	}
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
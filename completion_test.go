@@ -0,0 +1,262 @@
+package oldflag
+
+import (
+	"strings"
+	"testing"
+)
+
+// newCompletionSet returns the flag set the completion generators are
+// tested with.
+func newCompletionSet() *FlagSet {
+	f := NewFlagSet("tool", ContinueOnError)
+	f.Bool('a', false, "all")
+	f.BoolLong('q', "quiet", false, "quiet")
+	f.BoolLong(0, "dry-run", false, "do nothing")
+	f.StringLong('o', "output", "", "write to `file`")
+	f.ChoiceLong('m', "mode", "fast", []string{"fast", "safe"}, "the `mode`")
+	f.EnumVar(new(string), 'c', "auto", []Enum{{"auto", "when a terminal"}, {"never", "don't (ever)"}}, "colour `when`")
+	f.StringLong('g', "debug", "0", "debug `level`")
+	f.Lookup('g').Optional, f.Lookup('g').Implicit = true, "1"
+	f.MutuallyExclusive('a', 'q')
+	return f
+}
+
+// newCompletionTree returns the command tree the completion generators are
+// tested with.
+func newCompletionTree() *Command {
+	tool := NewCommand("tool", "a tool", nil)
+	tool.Persistent.Bool('v', false, "verbose")
+	remote := NewCommand("remote", "manage remotes", nil)
+	remote.Flags.Bool('f', false, "force")
+	add := NewCommand("add", "add a remote", func(cmd *Command, args []string) error { return nil })
+	add.Flags.StringLong('t', "track", "", "track `branch`")
+	remote.AddCommand(add)
+	tool.AddCommand(remote)
+	return tool
+}
+
+func TestWriteBashCompletion(t *testing.T) {
+	var b strings.Builder
+	if err := newCompletionSet().WriteBashCompletion(&b); err != nil {
+		t.Fatal(err)
+	}
+	// The rest of the script depends on the flags only through the lists
+	// of their names.
+	want := `# bash completion for tool
+
+_tool_kind() {
+	case "$1" in
+	'a') echo bool ;;
+	'c') echo arg ;;
+	'--dry-run') echo bool ;;
+	'g'|'--debug') echo optional ;;
+	'm'|'--mode') echo arg ;;
+	'o'|'--output') echo arg ;;
+	'q'|'--quiet') echo bool ;;
+	esac
+}
+
+_tool_values() {
+	case "$1" in
+	'c') compgen -W 'auto never' -- "$2" ;;
+	'm'|'--mode') compgen -W 'fast safe' -- "$2" ;;
+	*) compgen -f -- "$2" ;;
+	esac
+}
+
+_tool() {
+`
+	got := b.String()
+	if !strings.HasPrefix(got, want) {
+		t.Errorf("WriteBashCompletion:\n%s\nwant it to begin with:\n%s", got, want)
+	}
+	for _, want := range []string{
+		"\tlocal runes='a c g m o q' longs='--debug --dry-run --mode --output --quiet'\n",
+		"\ncomplete -F _tool tool\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("WriteBashCompletion:\n%s\nwant it to contain %q", got, want)
+		}
+	}
+}
+
+func TestWriteZshCompletion(t *testing.T) {
+	var b strings.Builder
+	if err := newCompletionSet().WriteZshCompletion(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := `#compdef tool
+
+_tool() {
+	_arguments -s -S \
+		'(-a -q --quiet)-a[all]' \
+		'-c+[colour when]:when:((auto\:when\ a\ terminal never\:don'\''t\ \(ever\)))' \
+		'--dry-run[do nothing]' \
+		'(-g --debug)-g-[debug level]::level:_files' \
+		'(-g --debug)--debug=-[debug level]::level:_files' \
+		'(-m --mode)-m+[the mode]:mode:(fast safe)' \
+		'(-m --mode)--mode=[the mode]:mode:(fast safe)' \
+		'(-o --output)-o+[write to file]:file:_files' \
+		'(-o --output)--output=[write to file]:file:_files' \
+		'(-q --quiet -a)-q[quiet]' \
+		'(-q --quiet -a)--quiet[quiet]' \
+		'*:file:_files'
+}
+
+if [[ $funcstack[1] == _tool ]]; then
+	_tool "$@"
+else
+	compdef _tool tool
+fi
+`
+	if got := b.String(); got != want {
+		t.Errorf("WriteZshCompletion:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Flags that may be given more than once, including counts, are
+	// offered again.
+	f := NewFlagSet("tool", ContinueOnError)
+	f.StringSliceLong('I', "include", nil, "search `dir`")
+	f.StringMap('D', nil, "define `key=value`")
+	f.Bool('n', false, "no defines")
+	f.CountLong('v', "verbose", 0, "more output")
+	f.MutuallyExclusive('D', 'n')
+	b.Reset()
+	if err := f.WriteZshCompletion(&b); err != nil {
+		t.Fatal(err)
+	}
+	want = `#compdef tool
+
+_tool() {
+	_arguments -s -S \
+		'(-n)*-D+[define key=value]:key=value:_files' \
+		'*-I+[search dir]:dir:_files' \
+		'*--include=[search dir]:dir:_files' \
+		'(-n -D)-n[no defines]' \
+		'*-v[more output]' \
+		'*--verbose[more output]' \
+		'*:file:_files'
+}
+
+if [[ $funcstack[1] == _tool ]]; then
+	_tool "$@"
+else
+	compdef _tool tool
+fi
+`
+	if got := b.String(); got != want {
+		t.Errorf("WriteZshCompletion with repeatable flags:\n%s\nwant:\n%s", got, want)
+	}
+
+	b.Reset()
+	if err := newCompletionTree().WriteZshCompletion(&b); err != nil {
+		t.Fatal(err)
+	}
+	want = `#compdef tool
+
+_tool() {
+	local curcontext=$curcontext state line
+	_arguments -C -s -S \
+		'-v[verbose]' \
+		'1:command:->command' \
+		'*::arg:->args'
+	case $state in
+	command)
+		local -a commands
+		commands=(
+			'remote:manage remotes'
+			'help:show the usage of a command'
+		)
+		_describe -t commands command commands
+		;;
+	args)
+		case $words[1] in
+		'remote') _tool_remote ;;
+		esac
+		;;
+	esac
+}
+
+_tool_remote() {
+	local curcontext=$curcontext state line
+	_arguments -C -s -S \
+		'-f[force]' \
+		'-v[verbose]' \
+		'1:command:->command' \
+		'*::arg:->args'
+	case $state in
+	command)
+		local -a commands
+		commands=(
+			'add:add a remote'
+			'help:show the usage of a command'
+		)
+		_describe -t commands command commands
+		;;
+	args)
+		case $words[1] in
+		'add') _tool_remote_add ;;
+		esac
+		;;
+	esac
+}
+
+_tool_remote_add() {
+	_arguments -s -S \
+		'(-t --track)-t+[track branch]:branch:_files' \
+		'(-t --track)--track=[track branch]:branch:_files' \
+		'-v[verbose]' \
+		'*:file:_files'
+}
+
+if [[ $funcstack[1] == _tool ]]; then
+	_tool "$@"
+else
+	compdef _tool tool
+fi
+`
+	if got := b.String(); got != want {
+		t.Errorf("Command.WriteZshCompletion:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWriteFishCompletion(t *testing.T) {
+	var b strings.Builder
+	if err := newCompletionSet().WriteFishCompletion(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := `# fish completion for tool
+
+complete -c tool -n 'not __fish_contains_opt -s q quiet' -s a -d all
+complete -c tool -s c -x -a '\'auto	when a terminal\' \'never	don\\\'t (ever)\'' -d 'colour when'
+complete -c tool -l dry-run -d 'do nothing'
+complete -c tool -s g -l debug -d 'debug level'
+complete -c tool -s m -l mode -x -a 'fast safe' -d 'the mode'
+complete -c tool -s o -l output -r -F -d 'write to file'
+complete -c tool -n 'not __fish_contains_opt -s a' -s q -l quiet -d quiet
+`
+	if got := b.String(); got != want {
+		t.Errorf("WriteFishCompletion:\n%s\nwant:\n%s", got, want)
+	}
+
+	// The flags of remote, such as -f, are not offered once add is seen.
+	b.Reset()
+	if err := newCompletionTree().WriteFishCompletion(&b); err != nil {
+		t.Fatal(err)
+	}
+	want = `# fish completion for tool
+
+complete -c tool -n __fish_use_subcommand -s v -d verbose
+complete -c tool -f -n __fish_use_subcommand -a remote -d 'manage remotes'
+
+complete -c tool -n '__fish_seen_subcommand_from remote; and not __fish_seen_subcommand_from add' -s f -d force
+complete -c tool -n '__fish_seen_subcommand_from remote; and not __fish_seen_subcommand_from add' -s v -d verbose
+complete -c tool -f -n '__fish_seen_subcommand_from remote; and not __fish_seen_subcommand_from add' -a add -d 'add a remote'
+
+complete -c tool -n '__fish_seen_subcommand_from remote; and __fish_seen_subcommand_from add' -s t -l track -r -F -d 'track branch'
+complete -c tool -n '__fish_seen_subcommand_from remote; and __fish_seen_subcommand_from add' -s v -d verbose
+`
+	if got := b.String(); got != want {
+		t.Errorf("Command.WriteFishCompletion:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,215 @@
+package oldflag
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteZshCompletion writes to w a zsh completion function for the flags of
+// the set, which must be named after the program they belong to. Each flag
+// is described by its usage message, and is marked as taking an argument,
+// attached or not, an optional attached argument, or none. Flags that may
+// not be given together, such as the rune and long names of the same flag,
+// or flags declared MutuallyExclusive, exclude each other. Repeatable
+// flags and counts are offered again after they are given. Arguments are
+// completed as by WriteBashCompletion, which also tells when the function
+// asks the program for completions.
+//
+// The function is meant to be installed as _program in a directory of
+// $fpath; it can also be sourced once compinit has run.
+func (f *FlagSet) WriteZshCompletion(w io.Writer) error {
+	prog := f.programName()
+	if prog == "" {
+		return fmt.Errorf("flag set has no name to complete")
+	}
+	if f.dynamic() {
+		return writeDynamicCompletion(w, zshDynamic, prog)
+	}
+	fn := "_" + shellName(prog)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "#compdef %s\n\n%s() {\n\t_arguments -s -S", prog, fn)
+	for _, spec := range zshSpecs(f) {
+		fmt.Fprintf(&b, " \\\n\t\t%s", shellQuote(spec))
+	}
+	fmt.Fprintf(&b, " \\\n\t\t'*:file:_files'\n}\n\n")
+	fmt.Fprintf(&b, zshTrailer, fn, prog)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteZshCompletion writes to w a zsh completion function for the
+// command-line flags. See FlagSet.WriteZshCompletion for details.
+func WriteZshCompletion(w io.Writer) error {
+	return CommandLine.WriteZshCompletion(w)
+}
+
+// WriteZshCompletion writes to w a zsh completion function for the command
+// tree rooted at c, named after the root. Each command completes its flags,
+// including the persistent flags it inherits, as FlagSet.WriteZshCompletion
+// does, and the names of its children. If any command has a Completer, the
+// function instead asks the program for completions at run time.
+func (c *Command) WriteZshCompletion(w io.Writer) error {
+	if c.dynamic() {
+		return writeDynamicCompletion(w, zshDynamic, c.Name)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "#compdef %s\n", c.Name)
+	c.writeZsh(&b)
+	fmt.Fprintf(&b, "\n"+zshTrailer, zshFunc(c), c.Name)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// zshFunc returns the name of the zsh completion function for a command.
+func zshFunc(c *Command) string {
+	return "_" + shellName(strings.ReplaceAll(c.Path(), " ", "_"))
+}
+
+// writeZsh writes the zsh completion functions for c and its descendants.
+func (c *Command) writeZsh(b *strings.Builder) {
+	c.inherit()
+	fmt.Fprintf(b, "\n%s() {\n", zshFunc(c))
+	if len(c.children) == 0 {
+		fmt.Fprintf(b, "\t_arguments -s -S")
+		for _, spec := range zshSpecs(c.Flags) {
+			fmt.Fprintf(b, " \\\n\t\t%s", shellQuote(spec))
+		}
+		fmt.Fprintf(b, " \\\n\t\t'*:file:_files'\n}\n")
+		return
+	}
+
+	fmt.Fprintf(b, "\tlocal curcontext=$curcontext state line\n\t_arguments -C -s -S")
+	for _, spec := range zshSpecs(c.Flags) {
+		fmt.Fprintf(b, " \\\n\t\t%s", shellQuote(spec))
+	}
+	fmt.Fprintf(b, " \\\n\t\t'1:command:->command' \\\n\t\t'*::arg:->args'\n")
+	fmt.Fprintf(b, "\tcase $state in\n\tcommand)\n\t\tlocal -a commands\n\t\tcommands=(")
+	for _, child := range c.children {
+		fmt.Fprintf(b, "\n\t\t\t%s", shellQuote(zshEscape(child.Name, ":")+":"+child.Short))
+	}
+	if c.Lookup("help") == nil {
+		fmt.Fprintf(b, "\n\t\t\t'help:show the usage of a command'")
+	}
+	fmt.Fprintf(b, "\n\t\t)\n\t\t_describe -t commands command commands\n\t\t;;\n")
+	fmt.Fprintf(b, "\targs)\n\t\tcase $words[1] in\n")
+	for _, child := range c.children {
+		fmt.Fprintf(b, "\t\t%s) %s ;;\n", shellQuote(child.Name), zshFunc(child))
+	}
+	fmt.Fprintf(b, "\t\tesac\n\t\t;;\n\tesac\n}\n")
+	for _, child := range c.children {
+		child.writeZsh(b)
+	}
+}
+
+// zshSpecs returns the _arguments specifications of the flags of f.
+func zshSpecs(f *FlagSet) []string {
+	var specs []string
+	for _, flag := range sortFlags(f.flags) {
+		name, usage := UnquoteUsage(flag)
+		if name == "" {
+			name = "value"
+		}
+		usage = strings.SplitN(usage, "\n", 2)[0]
+
+		var names []string
+		if flag.Name != 0 {
+			names = append(names, "-"+string(flag.Name))
+		}
+		if flag.Long != "" {
+			names = append(names, "--"+flag.Long)
+		}
+		// A flag that may be given more than once, such as a count given
+		// as -vvv, does not exclude itself.
+		rv, repeat := flag.Value.(repeatableFlag)
+		repeat = repeat && rv.IsRepeatable()
+		if _, ok := flag.Value.(*countValue); ok {
+			repeat = true
+		}
+		excluded := names
+		if repeat {
+			excluded = nil
+		}
+		for _, other := range f.exclusions(flag) {
+			if other.Name != 0 {
+				excluded = append(excluded[:len(excluded):len(excluded)], "-"+string(other.Name))
+			}
+			if other.Long != "" {
+				excluded = append(excluded[:len(excluded):len(excluded)], "--"+other.Long)
+			}
+		}
+		exclude := ""
+		if len(excluded) > 1 || repeat && len(excluded) > 0 {
+			exclude = "(" + strings.Join(excluded, " ") + ")"
+		}
+		if repeat {
+			exclude += "*"
+		}
+
+		action := "_files"
+		if c, descs := describedChoices(flag); descs != nil {
+			escaped := make([]string, len(c))
+			for i := range c {
+				escaped[i] = zshEscape(c[i], " ():") + "\\:" + zshEscape(descs[i], " ()")
+			}
+			action = "((" + strings.Join(escaped, " ") + "))"
+		} else if c != nil {
+			escaped := make([]string, len(c))
+			for i := range c {
+				escaped[i] = zshEscape(c[i], " ()")
+			}
+			action = "(" + strings.Join(escaped, " ") + ")"
+		}
+
+		for _, n := range names {
+			spec := exclude + zshEscape(n, ":[]")
+			long := strings.HasPrefix(n, "--")
+			switch flag.kind() {
+			case kindArg:
+				if long {
+					spec += "="
+				} else {
+					spec += "+"
+				}
+			case kindOptional:
+				if long {
+					spec += "=-"
+				} else {
+					spec += "-"
+				}
+			}
+			spec += "[" + zshEscape(usage, "[]") + "]"
+			switch flag.kind() {
+			case kindArg:
+				spec += ":" + zshEscape(name, ":") + ":" + action
+			case kindOptional:
+				spec += "::" + zshEscape(name, ":") + ":" + action
+			}
+			specs = append(specs, spec)
+		}
+	}
+	return specs
+}
+
+// zshEscape escapes the characters of chars, and backslashes, in s with
+// backslashes.
+func zshEscape(s, chars string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r == '\\' || strings.ContainsRune(chars, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// zshTrailer ends a zsh completion script. Its arguments are the name of
+// the completion function and the name of the program.
+const zshTrailer = `if [[ $funcstack[1] == %[1]s ]]; then
+	%[1]s "$@"
+else
+	compdef %[1]s %[2]s
+fi
+`
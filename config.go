@@ -0,0 +1,257 @@
+package oldflag
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ParseFile sets flags from the configuration file at path. A file whose
+// name ends in .json is read as by ParseJSON, and any other file as by
+// ParseINI.
+//
+// Values from a file override the defaults and the values from files read
+// before it. Parse applies the environment and the command line, which
+// override them in turn, so ParseFile should be called before Parse. If
+// the name of the file is itself given by a flag, call Parse, then
+// ParseFile, then Parse again with the same arguments.
+func (f *FlagSet) ParseFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintln(f.Output(), err)
+		return f.handleError(err)
+	}
+	defer file.Close()
+	if filepath.Ext(path) == ".json" {
+		return f.ParseJSON(file, path)
+	}
+	return f.ParseINI(file, path)
+}
+
+// ParseFile sets command-line flags from the configuration file at path.
+// See FlagSet.ParseFile for details.
+func ParseFile(path string) error {
+	return CommandLine.ParseFile(path)
+}
+
+// ParseINI sets flags from a configuration in a simple INI format, read from
+// r. The name of the configuration, typically a file name, is used in error
+// messages. Each line has the form
+//	key = value
+// where key is the long name or the rune name of a flag. The value may be
+// double-quoted, as a Go string literal. A boolean flag may be given by its
+// key alone, meaning key = true. Blank lines and lines starting with # or ;
+// are ignored. A line of the form
+//	[section]
+// starts a section; the keys that follow it are prefixed by "section.",
+// so that port in the [net] section sets --net.port.
+func (f *FlagSet) ParseINI(r io.Reader, name string) error {
+	f.startConfig()
+	scanner := bufio.NewScanner(r)
+	section := ""
+	for line := 1; scanner.Scan(); line++ {
+		s := strings.TrimSpace(scanner.Text())
+		if s == "" || s[0] == '#' || s[0] == ';' {
+			continue
+		}
+		if s[0] == '[' {
+			if s[len(s)-1] != ']' {
+				return f.configError(name, line, "bad section syntax: %s", s)
+			}
+			section = strings.TrimSpace(s[1 : len(s)-1])
+			if section != "" {
+				section += "."
+			}
+			continue
+		}
+
+		key, value, hasValue := s, "", false
+		if i := strings.IndexByte(s, '='); i >= 0 {
+			key, value, hasValue = strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+1:]), true
+		}
+		if key == "" {
+			return f.configError(name, line, "bad key syntax: %s", s)
+		}
+		key = section + key
+		if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+			v, err := strconv.Unquote(value)
+			if err != nil {
+				return f.configError(name, line, "bad quoted value for %s: %s", key, value)
+			}
+			value = v
+		}
+		if err := f.setConfig(key, value, hasValue, name, line); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintln(f.Output(), err)
+		return f.handleError(err)
+	}
+	return nil
+}
+
+// ParseINI sets command-line flags from a configuration in a simple INI
+// format. See FlagSet.ParseINI for details.
+func ParseINI(r io.Reader, name string) error {
+	return CommandLine.ParseINI(r, name)
+}
+
+// ParseJSON sets flags from a configuration in JSON, read from r. The name
+// of the configuration, typically a file name, is used in error messages.
+// The configuration is an object whose keys are the long names or the rune
+// names of flags. Strings, numbers and booleans are given to the flag as
+// text; null leaves the flag alone; an array sets the flag once for each of
+// its elements. A nested object prefixes its keys by the key it is under and
+// a dot, so that {"net": {"port": 80}} sets --net.port.
+func (f *FlagSet) ParseJSON(r io.Reader, name string) error {
+	f.startConfig()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		fmt.Fprintln(f.Output(), err)
+		return f.handleError(err)
+	}
+	d := &jsonConfig{f: f, data: data, name: name, dec: json.NewDecoder(bytes.NewReader(data))}
+	d.dec.UseNumber()
+	tok, err := d.dec.Token()
+	if err != nil {
+		return d.error(err)
+	}
+	if tok != json.Delim('{') {
+		return f.configError(name, d.line(), "configuration is not a JSON object")
+	}
+	return d.object("")
+}
+
+// ParseJSON sets command-line flags from a configuration in JSON.
+// See FlagSet.ParseJSON for details.
+func ParseJSON(r io.Reader, name string) error {
+	return CommandLine.ParseJSON(r, name)
+}
+
+// jsonConfig walks the tokens of a JSON configuration.
+type jsonConfig struct {
+	f    *FlagSet
+	data []byte
+	name string
+	dec  *json.Decoder
+}
+
+// line returns the line the decoder has read up to.
+func (d *jsonConfig) line() int {
+	return 1 + bytes.Count(d.data[:d.dec.InputOffset()], []byte("\n"))
+}
+
+// error reports a syntax error from the decoder.
+func (d *jsonConfig) error(err error) error {
+	return d.f.configError(d.name, d.line(), "%v", err)
+}
+
+// object sets the flags from the members of an object whose opening brace
+// has been read, prefixing their keys by prefix.
+func (d *jsonConfig) object(prefix string) error {
+	for d.dec.More() {
+		tok, err := d.dec.Token()
+		if err != nil {
+			return d.error(err)
+		}
+		key := prefix + tok.(string)
+		line := d.line()
+		tok, err = d.dec.Token()
+		if err != nil {
+			return d.error(err)
+		}
+		switch tok {
+		case json.Delim('{'):
+			err = d.object(key + ".")
+		case json.Delim('['):
+			for d.dec.More() {
+				tok, err = d.dec.Token()
+				if err != nil {
+					return d.error(err)
+				}
+				if err = d.scalar(key, tok, d.line()); err != nil {
+					return err
+				}
+			}
+			if _, err = d.dec.Token(); err != nil { // ']'
+				return d.error(err)
+			}
+		default:
+			err = d.scalar(key, tok, line)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if _, err := d.dec.Token(); err != nil { // '}'
+		return d.error(err)
+	}
+	return nil
+}
+
+// scalar sets the flag named by key from a JSON string, number or boolean.
+func (d *jsonConfig) scalar(key string, tok json.Token, line int) error {
+	var value string
+	switch v := tok.(type) {
+	case nil:
+		return nil
+	case string:
+		value = v
+	case json.Number:
+		value = v.String()
+	case bool:
+		value = strconv.FormatBool(v)
+	default:
+		return d.f.configError(d.name, line, "bad value for %s: nested arrays and objects are not allowed", key)
+	}
+	return d.f.setConfig(key, value, true, d.name, line)
+}
+
+// startConfig forgets which flags have been set from configurations, so
+// that a repeatable flag given in a configuration replaces, rather than
+// adds to, the values from those read before it.
+func (f *FlagSet) startConfig() {
+	for flag, source := range f.seen {
+		if source == FromFile {
+			delete(f.seen, flag)
+		}
+	}
+}
+
+// setConfig sets the flag named by key, either its long name or its rune
+// name, to value from line of the named configuration.
+func (f *FlagSet) setConfig(key, value string, hasValue bool, name string, line int) error {
+	flag := f.formalLong[key]
+	if r, size := utf8.DecodeRuneInString(key); flag == nil && size == len(key) {
+		flag = f.formal[r]
+	}
+	if flag == nil {
+		return f.configError(name, line, "flag provided but not defined: %s", key)
+	}
+	if !hasValue {
+		if fv, ok := flag.Value.(boolFlag); !ok || !fv.IsBoolFlag() {
+			return f.configError(name, line, "flag needs a value: %s", key)
+		}
+		value = "true"
+	}
+	if err := f.set(flag, value, Origin{Source: FromFile, File: name, Line: line}); err != nil {
+		return f.configError(name, line, "invalid value %q for flag %s: %v", value, flag.spelling(), err)
+	}
+	return nil
+}
+
+// configError prints to standard error a formatted error, prefixed by the
+// name and line of a configuration, and applies the error handling policy.
+func (f *FlagSet) configError(name string, line int, format string, a ...interface{}) error {
+	err := fmt.Errorf("%s:%d: %s", name, line, fmt.Sprintf(format, a...))
+	fmt.Fprintln(f.Output(), err)
+	return f.handleError(err)
+}
@@ -0,0 +1,132 @@
+package oldflag
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// newConfigSet returns a flag set for the configuration tests, and a
+// function formatting the values of its flags.
+func newConfigSet() (*FlagSet, func() string) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.SetOutput(io.Discard)
+	count := f.IntLong('c', "count", 0, "")
+	verbose := f.Bool('v', false, "")
+	port := f.IntLong(0, "net.port", 0, "")
+	include := f.StringSliceLong('I', "include", nil, "")
+	return f, func() string {
+		return fmt.Sprintf("c=%d v=%v port=%d I=%v", *count, *verbose, *port, *include)
+	}
+}
+
+func TestParseINI(t *testing.T) {
+	tests := []struct {
+		config string
+		want   string
+	}{
+		{"count = 3\nv\n", "c=3 v=true port=0 I=[]"},
+		{"# comment\n; comment\n\nc = \"4\"\n", "c=4 v=false port=0 I=[]"},
+		{"[net]\nport = 80\n[]\ninclude = a\ninclude = b\n", "c=0 v=false port=80 I=[a b]"},
+		{"count = 3\n\nnope = 1\n", "config:3: flag provided but not defined: nope"},
+		{"count = x\n", `config:1: invalid value "x" for flag -c: parse error`},
+		{"count\n", "config:1: flag needs a value: count"},
+		{"v = 1\n[net\n", "config:2: bad section syntax: [net"},
+		{"c = \"4\\x\"\n", `config:1: bad quoted value for c: "4\x"`},
+		{"[net]\ncount = 1\n", "config:2: flag provided but not defined: net.count"},
+	}
+	for _, tt := range tests {
+		f, values := newConfigSet()
+		got := ""
+		if err := f.ParseINI(strings.NewReader(tt.config), "config"); err != nil {
+			got = err.Error()
+		} else {
+			got = values()
+		}
+		if got != tt.want {
+			t.Errorf("ParseINI(%q):\n got %s\nwant %s", tt.config, got, tt.want)
+		}
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	tests := []struct {
+		config string
+		want   string
+	}{
+		{`{"count": 3, "v": true}`, "c=3 v=true port=0 I=[]"},
+		{`{"net": {"port": 80}, "include": ["a", "b"], "count": null}`, "c=0 v=false port=80 I=[a b]"},
+		{"{\n\"count\": 3,\n\"nope\": 1\n}", "config:3: flag provided but not defined: nope"},
+		{"{\n\"count\": \"x\"\n}", `config:2: invalid value "x" for flag -c: parse error`},
+		{"{\n\"include\": [\n\"a\",\n[\"b\"]\n]\n}", "config:4: bad value for include: nested arrays and objects are not allowed"},
+		{"[1]", "config:1: configuration is not a JSON object"},
+	}
+	for _, tt := range tests {
+		f, values := newConfigSet()
+		got := ""
+		if err := f.ParseJSON(strings.NewReader(tt.config), "config"); err != nil {
+			got = err.Error()
+		} else {
+			got = values()
+		}
+		if got != tt.want {
+			t.Errorf("ParseJSON(%q):\n got %s\nwant %s", tt.config, got, tt.want)
+		}
+	}
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	tests := []struct {
+		env  string
+		args []string
+		want string
+	}{
+		{"", nil, "c=1 v=false port=0 I=[x]"},
+		{"2", nil, "c=2 v=false port=0 I=[x]"},
+		{"2", []string{"-c3", "-Iy"}, "c=3 v=false port=0 I=[y]"},
+		{"", []string{"-Iy", "-Iz"}, "c=1 v=false port=0 I=[y z]"},
+	}
+	for _, tt := range tests {
+		f, values := newConfigSet()
+		f.EnvPrefix = "TEST_"
+		t.Setenv("TEST_COUNT", tt.env)
+		if tt.env == "" {
+			os.Unsetenv("TEST_COUNT")
+		}
+		if err := f.ParseINI(strings.NewReader("count = 1\ninclude = x\n"), "config"); err != nil {
+			t.Fatal(err)
+		}
+		if err := f.Parse(tt.args); err != nil {
+			t.Fatal(err)
+		}
+		if got := values(); got != tt.want {
+			t.Errorf("$TEST_COUNT=%q, args %q:\n got %s\nwant %s", tt.env, tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestConfigOverride(t *testing.T) {
+	// Each configuration overrides those read before it, including the
+	// repeatable flags it gives.
+	f, values := newConfigSet()
+	configs := []string{"include = a\ninclude = b\ncount = 1\n", "include = c\n"}
+	for i, config := range configs {
+		if err := f.ParseINI(strings.NewReader(config), fmt.Sprint("config", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.ParseJSON(strings.NewReader(`{"v": true}`), "config.json"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := values(), "c=1 v=true port=0 I=[c]"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if err := f.ParseJSON(strings.NewReader(`{"include": ["d", "e"]}`), "config.json"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := values(), "c=1 v=true port=0 I=[d e]"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,153 @@
+package oldflag
+
+import (
+	"fmt"
+	"strings"
+)
+
+// constraintKind tells what a constraint between flags asks for.
+type constraintKind int
+
+const (
+	atMostOne  constraintKind = iota // at most one of the flags is set
+	exactlyOne                       // exactly one of the flags is set
+	requires                         // if the first flag is set, so are the others
+)
+
+// A constraint is a relation between flags, checked at the end of Parse.
+type constraint struct {
+	kind  constraintKind
+	flags []*Flag
+}
+
+// MutuallyExclusive declares that at most one of the named flags may be
+// set. Parse fails if more than one is, and the synopsis lists them as
+// alternatives, as in [-a | -b].
+func (f *FlagSet) MutuallyExclusive(names ...rune) {
+	f.constraints = append(f.constraints, constraint{atMostOne, f.lookupAll(names)})
+}
+
+// MutuallyExclusive declares that at most one of the named command-line
+// flags may be set.
+func MutuallyExclusive(names ...rune) {
+	CommandLine.MutuallyExclusive(names...)
+}
+
+// ExactlyOne declares that exactly one of the named flags must be set.
+// Parse fails if none or more than one is, and the synopsis lists them as
+// required alternatives, as in {-x | -y}.
+func (f *FlagSet) ExactlyOne(names ...rune) {
+	f.constraints = append(f.constraints, constraint{exactlyOne, f.lookupAll(names)})
+}
+
+// ExactlyOne declares that exactly one of the named command-line flags
+// must be set.
+func ExactlyOne(names ...rune) {
+	CommandLine.ExactlyOne(names...)
+}
+
+// Requires declares that if the flag name is set, the flags named by
+// required must be set too. Parse fails otherwise, and PrintDefaults notes
+// the requirement.
+func (f *FlagSet) Requires(name rune, required ...rune) {
+	f.constraints = append(f.constraints, constraint{requires, f.lookupAll(append([]rune{name}, required...))})
+}
+
+// Requires declares that if the command-line flag name is set, the flags
+// named by required must be set too.
+func Requires(name rune, required ...rune) {
+	CommandLine.Requires(name, required...)
+}
+
+// lookupAll returns the named flags, which must have been defined.
+func (f *FlagSet) lookupAll(names []rune) []*Flag {
+	flags := make([]*Flag, len(names))
+	for i, name := range names {
+		flags[i] = f.formal[name]
+		if flags[i] == nil {
+			panic(fmt.Sprintf("%s flag constraint on undefined flag: -%c", f.name, name))
+		}
+	}
+	return flags
+}
+
+// checkConstraints reports, in one error, the constraints between flags
+// that are not met.
+func (f *FlagSet) checkConstraints() error {
+	if f.completing {
+		return nil
+	}
+	var problems []string
+	for _, c := range f.constraints {
+		var set, unset []string
+		for _, flag := range c.flags {
+			if f.actual[flag] {
+				set = append(set, flag.spelling())
+			} else {
+				unset = append(unset, flag.spelling())
+			}
+		}
+		switch {
+		case c.kind == requires && f.actual[c.flags[0]] && len(unset) > 0:
+			problems = append(problems, fmt.Sprintf("%s requires %s", set[0], strings.Join(unset, ", ")))
+		case c.kind != requires && len(set) > 1:
+			problems = append(problems, fmt.Sprintf("%s cannot be used together", strings.Join(set, ", ")))
+		case c.kind == exactlyOne && len(set) == 0:
+			problems = append(problems, fmt.Sprintf("one of %s is required", strings.Join(unset, ", ")))
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return f.failf("%s", strings.Join(problems, "; "))
+}
+
+// group returns the constraint listing flag as one of a group of
+// alternatives, or nil if there is none.
+func (f *FlagSet) group(flag *Flag) *constraint {
+	for i, c := range f.constraints {
+		if c.kind == requires {
+			continue
+		}
+		for _, member := range c.flags {
+			if member == flag {
+				return &f.constraints[i]
+			}
+		}
+	}
+	return nil
+}
+
+// requirements returns the flags that flag requires.
+func (f *FlagSet) requirements(flag *Flag) []*Flag {
+	var required []*Flag
+	for _, c := range f.constraints {
+		if c.kind == requires && c.flags[0] == flag {
+			required = append(required, c.flags[1:]...)
+		}
+	}
+	return required
+}
+
+// exclusions returns the flags that may not be given with flag.
+func (f *FlagSet) exclusions(flag *Flag) []*Flag {
+	var excluded []*Flag
+	for _, c := range f.constraints {
+		if c.kind == requires {
+			continue
+		}
+		member := false
+		for _, other := range c.flags {
+			member = member || other == flag
+		}
+		if !member {
+			continue
+		}
+		for _, other := range c.flags {
+			if other != flag {
+				excluded = append(excluded, other)
+			}
+		}
+	}
+	return excluded
+}
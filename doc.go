@@ -17,6 +17,11 @@ Or you can create custom flags that satisfy the Value interface (with
 pointer receivers) and couple them to flag parsing by
 	flag.Var(&flagVal, "name", "help message for flagname")
 For such flags, the default value is just the initial value of the variable.
+Bind defines a flag for each field of a struct, described by its tags:
+	var config struct {
+		Count int `flag:"c,count" default:"2" usage:"stop after n lines"`
+	}
+	flag.Bind(&config)
 After all flags are defined, call
 	flag.Parse()
 to parse the command line into the defined flags.
@@ -32,8 +37,20 @@ The following forms are permitted:
 	-flag
 	-flag=x
 	-flag x  // non-boolean flags only
-One or two minus signs may be used; they are equivalent.
-The last form is not permitted for boolean flags because the
+Flags named by single runes may be clustered, as in -abc, and follow the
+POSIX utility syntax guidelines: once a flag in a cluster takes an argument,
+the rest of the cluster is that argument, as in -ac73 or -ofile; if the
+cluster ends there, the next argument is, as in -ac 73.
+A flag defined with a long name, using VarLong or one of the *Long
+functions, may also be given as
+	--long
+	--long=x
+	--long x  // non-boolean flags only
+A flag whose argument is optional (see Flag.Optional) takes it only
+when attached, as in -xvalue, -x=value or --long=value.
+Unless a flag of that name is defined, --help calls Usage and --version
+calls Version.
+The -flag x and --long x forms are not permitted for boolean flags because the
 meaning of the command
 	cmd -x *
 where * is a Unix shell wildcard, will change if there is a file
@@ -41,14 +58,30 @@ called 0, false, etc. You must use the -flag=false form to turn
 off a boolean flag.
 Flag parsing stops just before the first non-flag argument
 ("-" is a non-flag argument) or after the terminator "--".
+If the FlagSet's Ordering is Permute, parsing instead continues past
+non-flag arguments, which are collected in order, up to "--".
+With ReturnInOrder, the flags and operands are also available, in
+command line order, from Events.
 Integer flags accept 1234, 0664, 0x1234 and may be negative.
 Boolean flags may be:
 	1, 0, t, f, T, F, true, false, TRUE, FALSE, True, False
 Duration flags accept any input valid for time.ParseDuration.
+A flag may also be bound to an environment variable, by setting its Env
+field or the FlagSet's EnvPrefix. Parse applies the environment after the
+defaults and before the command line. Flags may also be set from a
+configuration file, in a simple INI format or in JSON, with ParseFile
+before calling Parse, which gives the precedence
+	defaults < configuration file < environment < command line
+Each Flag records in its Origin where its current value came from, so that
+a program can log its effective configuration:
+	flag.VisitAll(func(f *flag.Flag) {
+		log.Printf("-%c=%s (from %s)", f.Name, f.Value, f.Origin)
+	})
 The default set of command-line flags is controlled by
 top-level functions.  The FlagSet type allows one to define
 independent sets of flags, such as to implement subcommands
 in a command-line interface. The methods of FlagSet are
 analogous to the top-level functions for the command-line
-flag set.
+flag set. The Command type builds a tree of subcommands, each with
+its own FlagSet, and routes the command line to the right one.
 */
@@ -17,7 +17,7 @@ import (
 	"unicode/utf8"
 )
 
-// ErrHelp is the error returned if the -help or -h flag is invoked
+// ErrHelp is the error returned if the --help or --version flag is invoked
 // but no such flag is defined.
 var ErrHelp = errors.New("flag: help requested")
 
@@ -50,6 +50,15 @@ func numError(err error) error {
 // the command-line parser makes -name equivalent to -name=true
 // rather than using the next command-line argument.
 //
+// If a Value has a Choices() []string method, shell completion offers
+// the values it returns for the flag instead of file names.
+//
+// If a Value has an IsRepeatable() bool method returning true, and a Reset()
+// method, the flag is repeatable: each occurrence adds to the value, except
+// that the first occurrence from a source, such as the command line, calls
+// Reset to replace the default or the value from the sources of lower
+// precedence.
+//
 // Set is called once, in command line order, for each flag present.
 // The flag package may call the String method with a zero-valued receiver,
 // such as a nil pointer.
@@ -108,6 +117,76 @@ const (
 	PanicOnError                         // Call panic with a descriptive error.
 )
 
+// Ordering defines how FlagSet.Parse treats flags that follow operands.
+type Ordering int
+
+// These constants cause FlagSet.Parse to order arguments as described.
+const (
+	// RequireOrder stops parsing flags at the first operand, as POSIX
+	// requires. It is what getopt does for an optstring starting with '+'.
+	RequireOrder Ordering = iota
+
+	// Permute keeps parsing flags after operands, as GNU getopt does by
+	// default, so that "cmd file -v" sets -v. The operands are left in
+	// Args in their original order. Everything after "--" is an operand.
+	// If the POSIXLY_CORRECT environment variable is set, Permute
+	// behaves as RequireOrder.
+	Permute
+
+	// ReturnInOrder parses as Permute does, but also records each flag
+	// occurrence and each operand, in command line order, as an Event.
+	// It is what getopt does for an optstring starting with '-', and is
+	// not affected by POSIXLY_CORRECT.
+	ReturnInOrder
+)
+
+// A Source tells where the current value of a flag came from.
+type Source int
+
+// These constants are the sources of the value of a flag, from the one
+// with the lowest precedence to the one with the highest.
+const (
+	FromDefault Source = iota // The default value given when the flag was defined.
+	FromFile                  // A configuration, through ParseFile, ParseINI or ParseJSON.
+	FromEnv                   // An environment variable.
+	FromArgs                  // The argument list given to Parse.
+	FromSet                   // A call to Set or SetLong.
+)
+
+// An Origin records where the current value of a flag came from. For the
+// flags of a command tree, Arg is an index in the argument list given to
+// Command.Parse.
+type Origin struct {
+	Source Source
+	Arg    int    // index in the argument list given to Parse, for FromArgs
+	File   string // name of the configuration, for FromFile
+	Line   int    // line in File, for FromFile
+	Env    string // environment variable, for FromEnv
+}
+
+// String returns a description of the origin, such as "default",
+// "argument 3", "app.conf:12", "$APP_PORT" or "Set".
+func (o Origin) String() string {
+	switch o.Source {
+	case FromFile:
+		return fmt.Sprintf("%s:%d", o.File, o.Line)
+	case FromEnv:
+		return "$" + o.Env
+	case FromArgs:
+		return fmt.Sprintf("argument %d", o.Arg)
+	case FromSet:
+		return "Set"
+	}
+	return "default"
+}
+
+// An Event is a flag occurrence or an operand, as recorded by Parse
+// when the Ordering is ReturnInOrder.
+type Event struct {
+	Flag  *Flag  // the flag that occurred, or nil for an operand
+	Value string // the value the flag was set to, or the operand
+}
+
 // A FlagSet represents a set of defined flags. The zero value of a FlagSet
 // has no name and has ContinueOnError error handling.
 //
@@ -133,33 +212,138 @@ type FlagSet struct {
 	// weird or otherwise non-standard flags.
 	MyParse func([]string) (int, error)
 
+	// Ordering controls whether flags may follow operands.
+	// The zero value is RequireOrder.
+	Ordering Ordering
+
+	// EnvPrefix, if not empty, binds every flag that has no Env to an
+	// environment variable named by EnvPrefix followed by the flag's long
+	// name, upper-cased and with - replaced by _, or by its rune name
+	// for an ASCII letter or digit, with its case kept so that -v and -V
+	// stay apart. With EnvPrefix "APP_", --dry-run is bound to APP_DRY_RUN
+	// and -p to APP_p.
+	EnvPrefix string
+
+	// OperandCompleter, if not nil, offers completions for operands.
+	// If it is nil, the shell completes file names.
+	OperandCompleter Completer
+
+	// ArgsUsage describes the operands in the synopsis line of the default
+	// usage message, such as "file ...". If it is empty, the synopsis
+	// describes the operands declared with OperandVar and the like.
+	ArgsUsage string
+
+	// Description and Epilog, if not empty, are printed by the default
+	// usage message before and after the flags; the epilog may hold
+	// examples or references to related programs.
+	Description string
+	Epilog      string
+
 	name          string
 	parsed        bool
-	actual        map[rune]*Flag
+	actual        map[*Flag]bool
+	seen          map[*Flag]Source // source each flag was last set from since Parse started
+	carried       map[*Flag]Source // persistent flags set by the parent command, for Command.Parse
+	deferred      func(*Flag) bool // required flags left for a child command to check, for Command.Parse
+	routed        bool             // Parse is run by Command.Parse
 	formal        map[rune]*Flag
-	args          []string // arguments after flags
+	formalLong    map[string]*Flag
+	flags         []*Flag      // all defined flags, in definition order
+	constraints   []constraint // constraints between flags, in declaration order
+	operands      []*Operand   // declared operands, in order
+	args          []string     // arguments after flags
+	argc          int          // number of arguments given to Parse
+	base          int          // index of those arguments in the ones given to Command.Parse
+	events        []Event      // flags and operands in order, for ReturnInOrder
+	terminated    bool         // Parse has seen the "--" terminator
+	needArg       *Flag        // flag that lacked its argument at the end of the arguments
+	completing    bool         // Parse is run by Complete
 	errorHandling ErrorHandling
 	output        io.Writer // nil means stderr; use Output() accessor
 }
 
 // A Flag represents the state of a flag.
 type Flag struct {
-	Name     rune   // name as it appears on command line
+	Name     rune   // name as it appears on command line, or 0 for a long-only flag
+	Long     string // long name as it appears after --, or "" if none
 	Usage    string // help message
 	Value    Value  // value as set
 	DefValue string // default value (as text); for usage message
+
+	// Optional makes the flag's argument optional, as with getopt's "x::".
+	// The argument must then be attached, as in -xvalue, -x=value or
+	// --long=value; the next argument is never consumed. If it is omitted,
+	// the flag is set to Implicit. To make an argument optional, set these
+	// fields after defining the flag:
+	//	f := flag.Lookup('g')
+	//	f.Optional, f.Implicit = true, "1"
+	Optional bool
+	Implicit string // value used when the optional argument is omitted
+
+	// Separator, if not empty, splits each value of a repeatable flag
+	// into several, as if the flag had been given once for each, so that
+	// with Separator "," -I a,b is the same as -I a -I b.
+	Separator string
+
+	// Group, if not empty, names the group PrintDefaults lists the flag
+	// under, such as "Network".
+	Group string
+
+	// Required, if true, makes Parse fail unless the flag has been set,
+	// whether by the argument list, the environment or a configuration.
+	Required bool
+
+	// Abbrev, if true, lets a flag whose Value has choices, such as one
+	// defined by Choice, be given any unique prefix of one of them, as in
+	// -m sa for -m safe.
+	Abbrev bool
+
+	// Duplicates tells what a map flag, such as one defined by StringMap,
+	// does with a key given more than once from the same source. A key
+	// from a source of higher precedence always replaces the values from
+	// the others.
+	Duplicates KeyPolicy
+
+	// Env names an environment variable that sets the flag, such as
+	// APP_PORT. Parse applies it after the default and before the command
+	// line, which overrides it.
+	Env string
+
+	// Completer, if not nil, offers completions for the flag's value at
+	// run time, for instance from state such as existing profile names.
+	// If it is nil, the Value itself may implement Completer.
+	Completer Completer
+
+	// Origin records where the current value came from. It is set each
+	// time the flag is set, so after Parse it tells, for instance, which
+	// argument or which line of a configuration file the value came from.
+	Origin Origin
+}
+
+// sortName returns the name a flag is sorted by: its rune name, or its long
+// name if it has no rune name.
+func (flag *Flag) sortName() string {
+	if flag.Name == 0 {
+		return flag.Long
+	}
+	return string(flag.Name)
+}
+
+// spelling returns the flag as it is spelled on the command line, such as
+// -o, or --output for a long-only flag.
+func (flag *Flag) spelling() string {
+	if flag.Name == 0 {
+		return "--" + flag.Long
+	}
+	return "-" + string(flag.Name)
 }
 
 // sortFlags returns the flags as a slice in lexicographical sorted order.
-func sortFlags(flags map[rune]*Flag) []*Flag {
+func sortFlags(flags []*Flag) []*Flag {
 	result := make([]*Flag, len(flags))
-	i := 0
-	for _, f := range flags {
-		result[i] = f
-		i++
-	}
+	copy(result, flags)
 	sort.Slice(result, func(i, j int) bool {
-		return result[i].Name < result[j].Name
+		return result[i].sortName() < result[j].sortName()
 	})
 	return result
 }
@@ -192,7 +376,7 @@ func (f *FlagSet) SetOutput(output io.Writer) {
 // VisitAll visits the flags in lexicographical order, calling fn for each.
 // It visits all flags, even those not set.
 func (f *FlagSet) VisitAll(fn func(*Flag)) {
-	for _, flag := range sortFlags(f.formal) {
+	for _, flag := range sortFlags(f.flags) {
 		fn(flag)
 	}
 }
@@ -206,8 +390,10 @@ func VisitAll(fn func(*Flag)) {
 // Visit visits the flags in lexicographical order, calling fn for each.
 // It visits only those flags that have been set.
 func (f *FlagSet) Visit(fn func(*Flag)) {
-	for _, flag := range sortFlags(f.actual) {
-		fn(flag)
+	for _, flag := range sortFlags(f.flags) {
+		if f.actual[flag] {
+			fn(flag)
+		}
 	}
 }
 
@@ -228,26 +414,146 @@ func Lookup(name rune) *Flag {
 	return CommandLine.formal[name]
 }
 
+// LookupLong returns the Flag structure of the flag with the given long name,
+// returning nil if none exists.
+func (f *FlagSet) LookupLong(name string) *Flag {
+	return f.formalLong[name]
+}
+
+// LookupLong returns the Flag structure of the command-line flag with the
+// given long name, returning nil if none exists.
+func LookupLong(name string) *Flag {
+	return CommandLine.formalLong[name]
+}
+
 // Set sets the value of the named flag.
 func (f *FlagSet) Set(name rune, value string) error {
 	flag, ok := f.formal[name]
 	if !ok {
-		return fmt.Errorf("no such flag -%v", name)
+		return fmt.Errorf("no such flag -%c", name)
+	}
+	return f.set(flag, value, Origin{Source: FromSet})
+}
+
+// Set sets the value of the named command-line flag.
+func Set(name rune, value string) error {
+	return CommandLine.Set(name, value)
+}
+
+// SetLong sets the value of the flag with the given long name.
+func (f *FlagSet) SetLong(name string, value string) error {
+	flag, ok := f.formalLong[name]
+	if !ok {
+		return fmt.Errorf("no such flag --%s", name)
 	}
-	err := flag.Value.Set(value)
+	return f.set(flag, value, Origin{Source: FromSet})
+}
+
+// SetLong sets the value of the command-line flag with the given long name.
+func SetLong(name string, value string) error {
+	return CommandLine.SetLong(name, value)
+}
+
+// set sets the value of flag and records that it has been set from origin.
+func (f *FlagSet) set(flag *Flag, value string, origin Origin) error {
+	err := f.setValue(flag, value, origin.Source)
 	if err != nil {
 		return err
 	}
-	if f.actual == nil {
-		f.actual = make(map[rune]*Flag)
+	f.markActual(flag, origin)
+	return nil
+}
+
+// setValue sets the Value of flag from source. A repeatable flag is reset
+// first unless it has already been set from source since Parse, or the
+// reading of the configuration, started, so that parsing the same
+// arguments again gives the same value, and its
+// value is split at its Separator. A count is likewise set back to its
+// default. A map flag applies its Duplicates
+// policy. A value abbreviating one of the choices of the flag is expanded
+// if Abbrev is set.
+func (f *FlagSet) setValue(flag *Flag, value string, source Source) error {
+	if flag.Abbrev {
+		value = expandChoice(flag, value)
+	}
+	last, ok := f.seen[flag]
+	first := !ok || last != source
+	f.see(flag, source)
+	if cv, ok := flag.Value.(*countValue); ok && first {
+		// A count starts over from its default.
+		cv.Set(flag.DefValue)
+	}
+	rv, ok := flag.Value.(repeatableFlag)
+	if !ok || !rv.IsRepeatable() {
+		return flag.Value.Set(value)
+	}
+	if first {
+		rv.Reset()
+	}
+	values := []string{value}
+	if flag.Separator != "" {
+		values = strings.Split(value, flag.Separator)
+	}
+	for _, v := range values {
+		if mv, ok := rv.(mapFlag); ok && flag.Duplicates != KeepLast {
+			if key, _, err := splitPair(v); err == nil && mv.hasKey(key) {
+				if flag.Duplicates == KeepFirst {
+					continue
+				}
+				return fmt.Errorf("duplicate key %q", key)
+			}
+		}
+		if err := rv.Set(v); err != nil {
+			return err
+		}
 	}
-	f.actual[name] = flag
 	return nil
 }
 
-// Set sets the value of the named command-line flag.
-func Set(name rune, value string) error {
-	return CommandLine.Set(name, value)
+// see records that flag has been set from source since Parse started.
+func (f *FlagSet) see(flag *Flag, source Source) {
+	if f.seen == nil {
+		f.seen = make(map[*Flag]Source)
+	}
+	f.seen[flag] = source
+}
+
+// markActual records that flag has been set from origin.
+func (f *FlagSet) markActual(flag *Flag, origin Origin) {
+	if f.actual == nil {
+		f.actual = make(map[*Flag]bool)
+	}
+	f.actual[flag] = true
+	flag.Origin = origin
+}
+
+// defines reports whether flag is one of the flags of f.
+func (f *FlagSet) defines(flag *Flag) bool {
+	return flag.Name != 0 && f.formal[flag.Name] == flag ||
+		flag.Long != "" && f.formalLong[flag.Long] == flag
+}
+
+// hasName reports whether f has a flag, flag itself or another, under the
+// name or the long name of flag.
+func (f *FlagSet) hasName(flag *Flag) bool {
+	_, name := f.formal[flag.Name]
+	_, long := f.formalLong[flag.Long]
+	return flag.Name != 0 && name || flag.Long != "" && long
+}
+
+// envName returns the environment variable bound to flag, or "" if none.
+func (f *FlagSet) envName(flag *Flag) string {
+	if flag.Env != "" || f.EnvPrefix == "" {
+		return flag.Env
+	}
+	if flag.Long != "" {
+		return f.EnvPrefix + strings.ToUpper(strings.ReplaceAll(flag.Long, "-", "_"))
+	}
+	r := flag.Name
+	if 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' {
+		return f.EnvPrefix + string(r)
+	}
+	return ""
 }
 
 // isZeroValue determines whether the string represents the zero
@@ -291,16 +597,20 @@ func UnquoteUsage(flag *Flag) (name string, usage string) {
 	switch flag.Value.(type) {
 	case boolFlag:
 		name = ""
-	case *durationValue:
+	case *durationValue, *durationSliceValue:
 		name = "duration"
 	case *float64Value:
 		name = "float"
-	case *intValue, *int64Value:
+	case *intValue, *int64Value, *intSliceValue:
 		name = "int"
-	case *stringValue:
+	case *stringValue, *stringSliceValue, *choiceValue:
 		name = "string"
 	case *uintValue, *uint64Value:
 		name = "uint"
+	case *stringMapValue, *valueMapValue:
+		name = "key=value"
+	case *intMapValue:
+		name = "key=int"
 	}
 	return
 }
@@ -309,54 +619,164 @@ func UnquoteUsage(flag *Flag) (name string, usage string) {
 // default values of all defined command-line flags in the set. See the
 // documentation for the global function PrintDefaults for more information.
 func (f *FlagSet) PrintDefaults() {
-	f.VisitAll(func(flag *Flag) {
-		s := fmt.Sprintf("  -%c", flag.Name) // Two spaces before -; see next two comments.
-		name, usage := UnquoteUsage(flag)
-		if len(name) > 0 {
-			s += " " + name
-		}
-		// Boolean flags of one ASCII letter are so common we
-		// treat them specially, putting their usage on the same line.
-		if len(s) <= 4 { // space, space, '-', 'x'.
-			s += "\t"
+	var entries []helpEntry
+	for _, group := range f.groups() {
+		for i, flag := range group.flags {
+			e := f.helpEntry(flag)
+			if i == 0 {
+				e.heading = group.name
+			}
+			entries = append(entries, e)
+		}
+	}
+	for _, op := range f.operands {
+		if op.Usage != "" {
+			entries = append(entries, helpEntry{names: op.synopsis(), usage: op.Usage})
+		}
+	}
+	fmt.Fprint(f.Output(), formatHelp(entries, terminalWidth(f.Output())))
+}
+
+// A flagGroup is a group of flags listed under a heading by PrintDefaults.
+type flagGroup struct {
+	name  string
+	flags []*Flag
+}
+
+// groups returns the flags of f by Group, in lexicographical order within
+// each group: first the flags in no group, then the groups in the order
+// their first flags were defined.
+func (f *FlagSet) groups() []flagGroup {
+	groups := []flagGroup{{}}
+	index := map[string]int{"": 0}
+	for _, flag := range f.flags {
+		if _, ok := index[flag.Group]; !ok {
+			index[flag.Group] = len(groups)
+			groups = append(groups, flagGroup{name: flag.Group})
+		}
+	}
+	for _, flag := range sortFlags(f.flags) {
+		i := index[flag.Group]
+		groups[i].flags = append(groups[i].flags, flag)
+	}
+	if len(groups[0].flags) == 0 {
+		groups = groups[1:]
+	}
+	return groups
+}
+
+// helpEntry returns the entry of flag in PrintDefaults.
+func (f *FlagSet) helpEntry(flag *Flag) helpEntry {
+	var s string
+	switch {
+	case flag.Long == "":
+		s = fmt.Sprintf("-%c", flag.Name)
+	case flag.Name == 0:
+		s = fmt.Sprintf("    --%s", flag.Long) // Line up with the long names below.
+	default:
+		s = fmt.Sprintf("-%c, --%s", flag.Name, flag.Long)
+	}
+	name, usage := UnquoteUsage(flag)
+	if flag.Optional {
+		if len(name) == 0 {
+			name = "value"
+		}
+		if flag.Long == "" {
+			s += "[" + name + "]"
 		} else {
-			// Four spaces before the tab triggers good alignment
-			// for both 4- and 8-space tab stops.
-			s += "\n    \t"
+			s += "[=" + name + "]"
 		}
-		s += strings.ReplaceAll(usage, "\n", "\n    \t")
+	} else if len(name) > 0 {
+		s += " " + name
+	}
 
-		if !isZeroValue(flag, flag.DefValue) {
-			if _, ok := flag.Value.(*stringValue); ok {
-				// put quotes on the value
-				s += fmt.Sprintf(" (default %q)", flag.DefValue)
-			} else {
-				s += fmt.Sprintf(" (default %v)", flag.DefValue)
-			}
+	notes := f.annotations(flag, func(s string, name bool) string { return s })
+	if len(notes) > 0 {
+		usage += " " + strings.Join(notes, " ")
+	}
+	names, descs := describedChoices(flag)
+	e := helpEntry{names: s, usage: strings.TrimSpace(usage)}
+	if descs != nil {
+		e.choices, e.descs = names, descs
+	}
+	return e
+}
+
+// annotations returns the notes PrintDefaults and WriteMan add to the usage
+// message of flag, such as "(default 7)" and "(required)". The text of the
+// notes is formatted by mark, which is told whether each piece of it is the
+// name of a flag or of an environment variable.
+func (f *FlagSet) annotations(flag *Flag, mark func(s string, name bool) string) []string {
+	var notes []string
+	if names, descs := describedChoices(flag); names != nil && descs == nil {
+		notes = append(notes, mark("one of "+strings.Join(names, ", "), false))
+	}
+	if !isZeroValue(flag, flag.DefValue) {
+		def := flag.DefValue
+		switch flag.Value.(type) {
+		case *stringValue, *choiceValue:
+			// put quotes on the value
+			def = strconv.Quote(def)
 		}
-		fmt.Fprint(f.Output(), s, "\n")
-	})
+		notes = append(notes, mark("default "+def, false))
+	}
+	if rv, ok := flag.Value.(repeatableFlag); ok && rv.IsRepeatable() {
+		notes = append(notes, mark("repeatable", false))
+	}
+	if flag.Required {
+		notes = append(notes, mark("required", false))
+	}
+	if required := f.requirements(flag); required != nil {
+		names := make([]string, len(required))
+		for i, r := range required {
+			names[i] = mark(r.spelling(), true)
+		}
+		notes = append(notes, mark("requires ", false)+strings.Join(names, mark(", ", false)))
+	}
+	if env := f.envName(flag); env != "" {
+		notes = append(notes, mark("env ", false)+mark("$"+env, true))
+	}
+	for i, note := range notes {
+		notes[i] = "(" + note + ")"
+	}
+	return notes
 }
 
 // PrintDefaults prints, to standard error unless configured otherwise,
 // a usage message showing the default settings of all defined
 // command-line flags.
-// For an integer valued flag x, the default output has the form
-//	-x int
-//		usage-message-for-x (default 7)
-// The usage message will appear on a separate line for anything but
-// a bool flag with a one-byte name. For bool flags, the type is
-// omitted and if the flag name is one byte the usage message appears
-// on the same line. The parenthetical default is omitted if the
-// default is the zero value for the type. The listed type, here int,
+// Each flag is listed on a line of its own with its names and the type of
+// its argument, followed by its usage message in a column lined up for all
+// the flags, as in
+//	-v                verbose output
+//	-x, --extra int   usage-message-for-x (default 7)
+//	    --long string usage-message-for-long
+// A flag whose argument is optional is listed as -x[int], or as
+// -x, --extra[=int] if it has a long name. For bool flags, the type is
+// omitted. A flag whose names are too wide for the column has its usage
+// message on the next line, and usage messages are wrapped to the width of
+// the terminal, or to $COLUMNS or 80 columns, with continuation lines
+// indented to the column. Widths are measured in terminal columns, so
+// names with wide runes, such as those of East Asian scripts, line up.
+// The parenthetical default is omitted if the
+// default is the zero value for the type. A repeatable flag, such as one
+// defined by StringSlice, is marked (repeatable), and its default is
+// listed as (default [a b]). A required flag is marked (required). The
+// allowed values of a flag with choices, such as one defined by Choice,
+// are listed as (one of a, b, c), or one per line below the usage message
+// if they are described. The listed type, here int,
 // can be changed by placing a back-quoted name in the flag's usage
 // string; the first such item in the message is taken to be a parameter
 // name to show in the message and the back quotes are stripped from
 // the message when displayed. For instance, given
 //	flag.String("I", "", "search `directory` for include files")
 // the output will be
-//	-I directory
-//		search directory for include files.
+//	-I directory  search directory for include files.
+// Flags in a Group are listed after the others, under a heading naming
+// the group, with the groups in the order their first flags were defined.
+// The declared operands that have a usage message are listed after the
+// flags, in the same columns, as in
+//	src ...       files to copy
 //
 // To change the destination for flag messages, call CommandLine.SetOutput.
 func PrintDefaults() {
@@ -365,12 +785,14 @@ func PrintDefaults() {
 
 // defaultUsage is the default function to print a usage message.
 func (f *FlagSet) defaultUsage() {
-	if f.name == "" {
-		fmt.Fprintf(f.Output(), "Usage:\n")
-	} else {
-		fmt.Fprintf(f.Output(), "Usage of %s:\n", f.name)
+	fmt.Fprint(f.Output(), f.Synopsis())
+	if f.Description != "" {
+		fmt.Fprintf(f.Output(), "\n%s\n\n", f.Description)
 	}
 	f.PrintDefaults()
+	if f.Epilog != "" {
+		fmt.Fprintf(f.Output(), "\n%s\n", f.Epilog)
+	}
 }
 
 // NOTE: Usage is not just defaultUsage(CommandLine)
@@ -381,14 +803,22 @@ func (f *FlagSet) defaultUsage() {
 // to CommandLine's output, which by default is os.Stderr.
 // It is called when an error occurs while parsing flags.
 // The function is a variable that may be changed to point to a custom function.
-// By default it prints a simple header and calls PrintDefaults; for details about the
-// format of the output and how to control it, see the documentation for PrintDefaults.
+// By default it prints a synopsis line, the Description of CommandLine,
+// the output of PrintDefaults and its Epilog; for details about the
+// format of the output and how to control it, see the documentation for Synopsis
+// and PrintDefaults.
 // Custom usage functions may choose to exit the program; by default exiting
 // happens anyway as the command line's error handling strategy is set to
 // ExitOnError.
 var Usage = func() {
-	fmt.Fprintf(CommandLine.Output(), "Usage of %s:\n", os.Args[0])
+	fmt.Fprint(CommandLine.Output(), Synopsis())
+	if CommandLine.Description != "" {
+		fmt.Fprintf(CommandLine.Output(), "\n%s\n\n", CommandLine.Description)
+	}
 	PrintDefaults()
+	if CommandLine.Epilog != "" {
+		fmt.Fprintf(CommandLine.Output(), "\n%s\n", CommandLine.Epilog)
+	}
 }
 
 // Version d
@@ -429,6 +859,26 @@ func (f *FlagSet) Args() []string { return f.args }
 // Args returns the non-flag command-line arguments.
 func Args() []string { return CommandLine.args }
 
+// Events returns the flag occurrences and operands seen by Parse, in
+// command line order. It is empty unless the Ordering is ReturnInOrder.
+// For example, given "-f a.txt x -f b.txt y" it returns the events
+// -f a.txt, x, -f b.txt and y, so the flags that preceded an operand
+// can be told apart from those that followed it.
+func (f *FlagSet) Events() []Event { return f.events }
+
+// Events returns the command-line flag occurrences and operands seen by
+// Parse, in command line order. It is empty unless the Ordering of
+// CommandLine is ReturnInOrder.
+func Events() []Event { return CommandLine.events }
+
+// addEvent records a flag occurrence, or an operand if flag is nil,
+// if the flag set returns arguments in order.
+func (f *FlagSet) addEvent(flag *Flag, value string) {
+	if f.Ordering == ReturnInOrder {
+		f.events = append(f.events, Event{flag, value})
+	}
+}
+
 // Var defines a flag with the specified name and usage string. The type and
 // value of the flag are represented by the first argument, of type Value, which
 // typically holds a user-defined implementation of Value. For instance, the
@@ -436,36 +886,81 @@ func Args() []string { return CommandLine.args }
 // of strings by giving the slice the methods of Value; in particular, Set would
 // decompose the comma-separated string into the slice.
 func (f *FlagSet) Var(value Value, name rune, usage string) {
+	f.VarLong(value, name, "", usage)
+}
+
+// Var defines a flag with the specified name and usage string. The type and
+// value of the flag are represented by the first argument, of type Value, which
+// typically holds a user-defined implementation of Value. For instance, the
+// caller could create a flag that turns a comma-separated string into a slice
+// of strings by giving the slice the methods of Value; in particular, Set would
+// decompose the comma-separated string into the slice.
+func Var(value Value, name rune, usage string) {
+	CommandLine.Var(value, name, usage)
+}
+
+// VarLong is like Var, but also gives the flag a long name, which is
+// used on the command line as --long. Either name may be omitted by passing
+// 0 or "", but not both. Both names refer to the same Flag.
+func (f *FlagSet) VarLong(value Value, name rune, long string, usage string) {
 	if !utf8.ValidRune(name) {
 		panic(fmt.Sprintf("flag name 0x%X outide Unicode range", name))
 	}
+	if name == 0 && long == "" {
+		panic("flag defined without a name")
+	}
+	if strings.HasPrefix(long, "-") || strings.Contains(long, "=") {
+		panic(fmt.Sprintf("flag long name %q begins with - or contains =", long))
+	}
 	// Remember the default value as a string; it won't change.
-	flag := &Flag{name, usage, value, value.String()}
+	f.addFlag(&Flag{Name: name, Long: long, Usage: usage, Value: value, DefValue: value.String()})
+}
+
+// addFlag adds a defined flag to the flag set under its names.
+func (f *FlagSet) addFlag(flag *Flag) {
+	name, long := flag.Name, flag.Long
 	_, alreadythere := f.formal[name]
-	if alreadythere {
+	if name == 0 {
+		alreadythere = false
+	}
+	_, alreadythereLong := f.formalLong[long]
+	if long == "" {
+		alreadythereLong = false
+	}
+	if alreadythere || alreadythereLong {
+		redefined := string(name)
+		if !alreadythere {
+			redefined = "--" + long
+		}
 		var msg string
 		if f.name == "" {
-			msg = fmt.Sprintf("flag redefined: %c", name)
+			msg = fmt.Sprintf("flag redefined: %s", redefined)
 		} else {
-			msg = fmt.Sprintf("%s flag redefined: %c", f.name, name)
+			msg = fmt.Sprintf("%s flag redefined: %s", f.name, redefined)
 		}
 		fmt.Fprintln(f.Output(), msg)
 		panic(msg) // Happens only if flags are declared with identical names
 	}
-	if f.formal == nil {
-		f.formal = make(map[rune]*Flag)
+	if name != 0 {
+		if f.formal == nil {
+			f.formal = make(map[rune]*Flag)
+		}
+		f.formal[name] = flag
+	}
+	if long != "" {
+		if f.formalLong == nil {
+			f.formalLong = make(map[string]*Flag)
+		}
+		f.formalLong[long] = flag
 	}
-	f.formal[name] = flag
+	f.flags = append(f.flags, flag)
 }
 
-// Var defines a flag with the specified name and usage string. The type and
-// value of the flag are represented by the first argument, of type Value, which
-// typically holds a user-defined implementation of Value. For instance, the
-// caller could create a flag that turns a comma-separated string into a slice
-// of strings by giving the slice the methods of Value; in particular, Set would
-// decompose the comma-separated string into the slice.
-func Var(value Value, name rune, usage string) {
-	CommandLine.Var(value, name, usage)
+// VarLong is like Var, but also gives the command-line flag a long name,
+// which is used on the command line as --long. Either name may be omitted
+// by passing 0 or "", but not both.
+func VarLong(value Value, name rune, long string, usage string) {
+	CommandLine.VarLong(value, name, long, usage)
 }
 
 // failf prints to standard error a formatted error and usage message and
@@ -477,6 +972,18 @@ func (f *FlagSet) failf(format string, a ...interface{}) error {
 	return err
 }
 
+// handleError carries out the error handling policy of the flag set for
+// an error from parsing, returning err if the policy is ContinueOnError.
+func (f *FlagSet) handleError(err error) error {
+	switch f.errorHandling {
+	case ExitOnError:
+		os.Exit(2)
+	case PanicOnError:
+		panic(err)
+	}
+	return err
+}
+
 // usage calls the Usage method for the flag set if one is specified,
 // or the appropriate default usage function otherwise.
 func (f *FlagSet) usage() {
@@ -513,23 +1020,19 @@ func (f *FlagSet) parseOne() (bool, error) {
 	}
 
 	s := f.args[0]
-
-	if !f.NoHelp && s == "--help" {
-		f.usage()
-		return false, ErrHelp
-	}
-	if f.Version != nil && s == "--version" {
-		f.Version()
-		return false, ErrHelp
-	}
+	index := f.base + f.argc - len(f.args)
 
 	if len(s) < 2 || s[0] != '-' {
 		return false, nil
 	}
 	if s == "--" {
 		f.args = f.args[1:]
+		f.terminated = true
 		return false, nil
 	}
+	if s[1] == '-' {
+		return f.parseLong(s[2:])
+	}
 
 	for i, r := range s[1:] {
 		flag, have := f.formal[r]
@@ -537,25 +1040,41 @@ func (f *FlagSet) parseOne() (bool, error) {
 			return false, f.failf("flag provided but not defined: -%c", r)
 		}
 
+		// Once a flag takes an argument, the rest of s is that argument,
+		// as in -ac73; if s ends here, the next argument is, as in -ac 73.
 		fv := flag.Value
+		setArg := func(value string) error { return f.setValue(flag, value, FromArgs) }
 		var err error
 		var value string
-		if i+1 < len(s) && s[i+1] == '=' {
-			value = s[i+2:]
-			err = fv.Set(value)
+		rest := s[1+i+utf8.RuneLen(r):]
+		last := false // the flag took the rest of s
+		if flag.Optional {
+			// The next argument is never used.
+			value = flag.Implicit
+			if rest != "" {
+				value = strings.TrimPrefix(rest, "=")
+			}
+			err = setArg(value)
+			last = true
+		} else if strings.HasPrefix(rest, "=") {
+			value = rest[1:]
+			err = setArg(value)
+			last = true
 		} else if fvb, ok := fv.(boolFlag); ok && fvb.IsBoolFlag() {
 			value = "true"
-			err = fvb.Set(value)
-		} else if i > len(s) {
-			value = s[i+1:]
-			err = fv.Set(value)
+			err = setArg(value)
+		} else if rest != "" {
+			value = rest
+			err = setArg(value)
+			last = true
 		} else if len(f.args) > skip+1 {
 			value = f.args[skip+1]
-			err = fv.Set(value)
+			err = setArg(value)
 			if err == nil {
 				skip++
 			}
 		} else {
+			f.needArg = flag
 			return false, f.failf("flag needs an argument: -%c", flag.Name)
 		}
 
@@ -563,10 +1082,11 @@ func (f *FlagSet) parseOne() (bool, error) {
 			return false, f.failf("invalid value %q for flag -%c: %v", value, flag.Name, err)
 		}
 
-		if f.actual == nil {
-			f.actual = make(map[rune]*Flag)
+		f.markActual(flag, Origin{Source: FromArgs, Arg: index})
+		f.addEvent(flag, value)
+		if last {
+			break
 		}
-		f.actual[flag.Name] = flag
 	}
 
 	f.args = f.args[skip+1:]
@@ -640,33 +1160,180 @@ func (f *FlagSet) parseOne() (bool, error) {
 	*/
 }
 
+// parseLong parses one long flag, given without its leading "--".
+// --help and --version are handled here unless a flag of that name
+// has been defined.
+func (f *FlagSet) parseLong(name string) (bool, error) {
+	index := f.base + f.argc - len(f.args)
+	f.args = f.args[1:]
+	hasValue := false
+	value := ""
+	if i := strings.IndexByte(name, '='); i >= 0 {
+		name, value, hasValue = name[:i], name[i+1:], true
+	}
+	if len(name) == 0 {
+		return false, f.failf("bad flag syntax: --%s=%s", name, value)
+	}
+
+	flag, have := f.formalLong[name]
+	if !have {
+		if name == "help" && !f.NoHelp && !hasValue {
+			f.usage()
+			return false, ErrHelp
+		}
+		if name == "version" && f.Version != nil && !hasValue {
+			f.Version()
+			return false, ErrHelp
+		}
+		return false, f.failf("flag provided but not defined: --%s", name)
+	}
+
+	if flag.Optional { // special case: the arg is never the next one
+		if !hasValue {
+			value = flag.Implicit
+		}
+	} else if fv, ok := flag.Value.(boolFlag); ok && fv.IsBoolFlag() { // special case: doesn't need an arg
+		if !hasValue {
+			value = "true"
+		}
+	} else if !hasValue {
+		if len(f.args) == 0 {
+			f.needArg = flag
+			return false, f.failf("flag needs an argument: --%s", name)
+		}
+		value, f.args = f.args[0], f.args[1:]
+	}
+	if err := f.setValue(flag, value, FromArgs); err != nil {
+		return false, f.failf("invalid value %q for flag --%s: %v", value, name, err)
+	}
+	f.markActual(flag, Origin{Source: FromArgs, Arg: index})
+	f.addEvent(flag, value)
+	return true, nil
+}
+
 // Parse parses flag definitions from the argument list, which should not
 // include the command name. Must be called after all flags in the FlagSet
 // are defined and before flags are accessed by the program.
-// The return value will be ErrHelp if -help or -h were set but not defined.
+// If the first argument is "__complete", Parse instead writes completions
+// for the remaining arguments to standard output, as Complete does, and
+// then exits with status 0 if the error handling policy is ExitOnError, or
+// returns an error wrapping ErrHelp otherwise. The flag sets of the commands
+// a Command routes arguments to leave "__complete" to Command.Parse.
+// Flags bound to environment variables are set from the environment first,
+// so that the argument list overrides them.
+// The return value will be ErrHelp if --help or --version were set but not defined.
 func (f *FlagSet) Parse(arguments []string) error {
 	f.parsed = true
 	f.args = arguments
+	f.argc = len(arguments)
+	f.events = nil
+	f.seen = nil
+	for flag, source := range f.carried {
+		if f.defines(flag) {
+			f.see(flag, source)
+			f.markActual(flag, flag.Origin)
+		}
+	}
+	f.terminated = false
+	f.needArg = nil
+	if len(arguments) > 0 && arguments[0] == completeArg && !f.completing && !f.routed {
+		f.Complete(os.Stdout, arguments[1:])
+		return f.completed()
+	}
+	if err := f.parseEnv(); err != nil {
+		return f.handleError(err)
+	}
+	permute := f.ordering() != RequireOrder
+	var operands []string
 	for {
+		n := len(f.args)
 		seen, err := f.parseOne()
 		if seen {
 			continue
 		}
 		if err == nil {
+			// parseOne stopped at an operand, or consumed the "--"
+			// terminator, which ends the flags in any case.
+			if permute && len(f.args) > 0 && len(f.args) == n {
+				operands = append(operands, f.args[0])
+				f.addEvent(nil, f.args[0])
+				f.args = f.args[1:]
+				continue
+			}
 			break
 		}
-		switch f.errorHandling {
-		case ContinueOnError:
-			return err
-		case ExitOnError:
-			os.Exit(2)
-		case PanicOnError:
-			panic(err)
+		return f.handleError(err)
+	}
+	for _, arg := range f.args {
+		f.addEvent(nil, arg)
+	}
+	if operands != nil {
+		f.args = append(operands, f.args...)
+	}
+	if err := f.checkRequired(); err != nil {
+		return f.handleError(err)
+	}
+	if err := f.checkConstraints(); err != nil {
+		return f.handleError(err)
+	}
+	if err := f.parseOperands(); err != nil {
+		return f.handleError(err)
+	}
+	return nil
+}
+
+// checkRequired reports, in one error, the required flags that have not
+// been set.
+func (f *FlagSet) checkRequired() error {
+	if f.completing {
+		return nil
+	}
+	var missing []string
+	for _, flag := range sortFlags(f.flags) {
+		if flag.Required && !f.actual[flag] && (f.deferred == nil || !f.deferred(flag)) {
+			missing = append(missing, flag.spelling())
+		}
+	}
+	switch len(missing) {
+	case 0:
+		return nil
+	case 1:
+		return f.failf("missing required flag: %s", missing[0])
+	}
+	return f.failf("missing required flags: %s", strings.Join(missing, ", "))
+}
+
+// parseEnv sets the flags bound to environment variables that are set,
+// except for those a parent command has set from the environment or its
+// arguments.
+func (f *FlagSet) parseEnv() error {
+	for _, flag := range f.flags {
+		env := f.envName(flag)
+		if env == "" || f.seen[flag] >= FromEnv {
+			continue
+		}
+		value, ok := os.LookupEnv(env)
+		if !ok {
+			continue
+		}
+		if err := f.set(flag, value, Origin{Source: FromEnv, Env: env}); err != nil {
+			return f.failf("invalid value %q for flag %s from $%s: %v", value, flag.spelling(), env, err)
 		}
 	}
 	return nil
 }
 
+// ordering returns the effective ordering of the flag set, taking
+// POSIXLY_CORRECT into account.
+func (f *FlagSet) ordering() Ordering {
+	if f.Ordering == Permute {
+		if _, ok := os.LookupEnv("POSIXLY_CORRECT"); ok {
+			return RequireOrder
+		}
+	}
+	return f.Ordering
+}
+
 // Parsed reports whether f.Parse has been called.
 func (f *FlagSet) Parsed() bool {
 	return f.parsed
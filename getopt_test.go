@@ -0,0 +1,225 @@
+package oldflag
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// getoptTests are reference cases for getopt(3) with the optstring "abc:o:".
+// The expected results are what getopt reports for the same argv.
+var getoptTests = []struct {
+	args []string
+	want string // flags and operands as formatted by getoptResult, or the error
+}{
+	{[]string{}, `a=false b=false c="" o="" args=[]`},
+	{[]string{"-a"}, `a=true b=false c="" o="" args=[]`},
+	{[]string{"-ab"}, `a=true b=true c="" o="" args=[]`},
+	{[]string{"-a", "-b"}, `a=true b=true c="" o="" args=[]`},
+	{[]string{"-c73"}, `a=false b=false c="73" o="" args=[]`},
+	{[]string{"-c", "73"}, `a=false b=false c="73" o="" args=[]`},
+	{[]string{"-ac73"}, `a=true b=false c="73" o="" args=[]`},
+	{[]string{"-ac", "73", "x"}, `a=true b=false c="73" o="" args=["x"]`},
+	{[]string{"-ofile", "x"}, `a=false b=false c="" o="file" args=["x"]`},
+	{[]string{"-abcoa", "x"}, `a=true b=true c="oa" o="" args=["x"]`},
+	{[]string{"-co", "-a"}, `a=true b=false c="o" o="" args=[]`},
+	{[]string{"-o", "-a"}, `a=false b=false c="" o="-a" args=[]`},
+	{[]string{"-c", "--"}, `a=false b=false c="--" o="" args=[]`},
+	{[]string{"-c", ""}, `a=false b=false c="" o="" args=[]`},
+	{[]string{"-c1", "-c2"}, `a=false b=false c="2" o="" args=[]`},
+	{[]string{"-a", "--", "-b"}, `a=true b=false c="" o="" args=["-b"]`},
+	{[]string{"-a", "-", "-b"}, `a=true b=false c="" o="" args=["-" "-b"]`},
+	{[]string{"x", "-a"}, `a=false b=false c="" o="" args=["x" "-a"]`},
+	{[]string{"-a", "x", "--", "-b"}, `a=true b=false c="" o="" args=["x" "--" "-b"]`},
+	{[]string{"-c"}, `flag needs an argument: -c`},
+	{[]string{"-ac"}, `flag needs an argument: -c`},
+	{[]string{"-z"}, `flag provided but not defined: -z`},
+	{[]string{"-az"}, `flag provided but not defined: -z`},
+	{[]string{"-a-"}, `flag provided but not defined: --`},
+
+	// Extensions to getopt: an = may separate a flag from its argument.
+	{[]string{"-c=73"}, `a=false b=false c="73" o="" args=[]`},
+	{[]string{"-ac=73"}, `a=true b=false c="73" o="" args=[]`},
+	{[]string{"-a=false", "-b"}, `a=false b=true c="" o="" args=[]`},
+}
+
+func getoptResult(args []string, ordering Ordering) string {
+	fs := NewFlagSet("getopt", ContinueOnError)
+	fs.Ordering = ordering
+	fs.SetOutput(io.Discard)
+	a := fs.Bool('a', false, "")
+	b := fs.Bool('b', false, "")
+	c := fs.String('c', "", "")
+	o := fs.String('o', "", "")
+	if err := fs.Parse(args); err != nil {
+		return err.Error()
+	}
+	return fmt.Sprintf("a=%v b=%v c=%q o=%q args=%q", *a, *b, *c, *o, fs.Args())
+}
+
+func TestGetopt(t *testing.T) {
+	for _, tt := range getoptTests {
+		if got := getoptResult(tt.args, RequireOrder); got != tt.want {
+			t.Errorf("Parse(%q):\n got %s\nwant %s", tt.args, got, tt.want)
+		}
+	}
+}
+
+// optionalTests are reference cases for GNU getopt_long(3) with the
+// optstring "ag::" and the long option "debug" with an optional argument
+// for -g, whose argument is 1 when it is omitted.
+var optionalTests = []struct {
+	args []string
+	want string
+}{
+	{[]string{}, `a=false g="0" args=[]`},
+	{[]string{"-g"}, `a=false g="1" args=[]`},
+	{[]string{"-g5"}, `a=false g="5" args=[]`},
+	{[]string{"-g=6"}, `a=false g="6" args=[]`},
+	{[]string{"-ag", "7"}, `a=true g="1" args=["7"]`},
+	{[]string{"-ag7"}, `a=true g="7" args=[]`},
+	{[]string{"-ga"}, `a=false g="a" args=[]`},
+	{[]string{"--debug", "8"}, `a=false g="1" args=["8"]`},
+	{[]string{"--debug=9"}, `a=false g="9" args=[]`},
+	{[]string{"--debug="}, `a=false g="" args=[]`},
+	{[]string{"-g", "-a"}, `a=true g="1" args=[]`},
+}
+
+func TestOptional(t *testing.T) {
+	for _, tt := range optionalTests {
+		fs := NewFlagSet("getopt", ContinueOnError)
+		fs.SetOutput(io.Discard)
+		a := fs.Bool('a', false, "")
+		g := fs.StringLong('g', "debug", "0", "")
+		flag := fs.Lookup('g')
+		flag.Optional, flag.Implicit = true, "1"
+		got := ""
+		if err := fs.Parse(tt.args); err != nil {
+			got = err.Error()
+		} else {
+			got = fmt.Sprintf("a=%v g=%q args=%q", *a, *g, fs.Args())
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q):\n got %s\nwant %s", tt.args, got, tt.want)
+		}
+	}
+}
+
+// permuteTests are reference cases for GNU getopt(3) with the optstring
+// "abc:o:", which permutes argv by default.
+var permuteTests = []struct {
+	args []string
+	want string
+}{
+	{[]string{"x", "-a"}, `a=true b=false c="" o="" args=["x"]`},
+	{[]string{"x", "-c", "73", "y", "-b"}, `a=false b=true c="73" o="" args=["x" "y"]`},
+	{[]string{"x", "-", "-a"}, `a=true b=false c="" o="" args=["x" "-"]`},
+	{[]string{"x", "--", "-a", "y"}, `a=false b=false c="" o="" args=["x" "-a" "y"]`},
+	{[]string{"-o", "x", "y", "-ay"}, `flag provided but not defined: -y`},
+}
+
+func TestPermute(t *testing.T) {
+	for _, tt := range permuteTests {
+		if got := getoptResult(tt.args, Permute); got != tt.want {
+			t.Errorf("Parse(%q):\n got %s\nwant %s", tt.args, got, tt.want)
+		}
+	}
+
+	t.Setenv("POSIXLY_CORRECT", "")
+	args := []string{"x", "-a"}
+	want := `a=false b=false c="" o="" args=["x" "-a"]`
+	if got := getoptResult(args, Permute); got != want {
+		t.Errorf("Parse(%q) with POSIXLY_CORRECT:\n got %s\nwant %s", args, got, want)
+	}
+}
+
+func TestReturnInOrder(t *testing.T) {
+	fs := NewFlagSet("getopt", ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Ordering = ReturnInOrder
+	fs.Bool('a', false, "")
+	fs.String('f', "", "")
+	args := []string{"-f", "a.txt", "x", "-af", "b.txt", "y", "--", "-a"}
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range fs.Events() {
+		if e.Flag == nil {
+			got = append(got, e.Value)
+		} else {
+			got = append(got, fmt.Sprintf("-%c=%s", e.Flag.Name, e.Value))
+		}
+	}
+	want := `["-f=a.txt" "x" "-a=true" "-f=b.txt" "y" "-a"]`
+	if s := fmt.Sprintf("%q", got); s != want {
+		t.Errorf("Events() = %s, want %s", s, want)
+	}
+	if s := fmt.Sprintf("%q", fs.Args()); s != `["x" "y" "-a"]` {
+		t.Errorf("Args() = %s", s)
+	}
+}
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{[]string{"-vvv"}, 3},
+		{[]string{"-avva"}, 2},
+		{[]string{"-v=3", "-v"}, 4},
+		{[]string{"-vv", "-v=0", "-v"}, 1},
+		{[]string{"--verbose", "-v"}, 2},
+		{[]string{"--verbose=5"}, 5},
+	}
+	for _, tt := range tests {
+		fs := NewFlagSet("getopt", ContinueOnError)
+		fs.Bool('a', false, "")
+		v := fs.CountLong('v', "verbose", 0, "")
+		if err := fs.Parse(tt.args); err != nil {
+			t.Fatal(err)
+		}
+		if *v != tt.want {
+			t.Errorf("Parse(%q): count = %d, want %d", tt.args, *v, tt.want)
+		}
+	}
+
+	// Parsing the same arguments again, as after ParseFile, counts afresh.
+	fs := NewFlagSet("getopt", ContinueOnError)
+	v := fs.Count('v', 1, "")
+	for i := 0; i < 2; i++ {
+		if err := fs.Parse([]string{"-vv"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if *v != 3 {
+		t.Errorf("Parse(-vv) twice: count = %d, want 3", *v)
+	}
+}
+
+func TestOrigin(t *testing.T) {
+	fs := NewFlagSet("getopt", ContinueOnError)
+	fs.Ordering = Permute
+	fs.Bool('a', false, "")
+	fs.Int('c', 0, "")
+	fs.String('o', "", "")
+	fs.StringLong('f', "file", "", "")
+	fs.String('e', "", "")
+	fs.Lookup('e').Env = "TEST_E"
+	t.Setenv("TEST_E", "x")
+	if err := fs.ParseINI(strings.NewReader("\nfile = a.txt\no = out\n"), "app.conf"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Parse([]string{"x", "-ac", "3", "y", "--file=b", "--", "-o"}); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, name := range "acefo" {
+		got = append(got, fmt.Sprintf("-%c=%s", name, fs.Lookup(name).Origin))
+	}
+	want := `["-a=argument 1" "-c=argument 1" "-e=$TEST_E" "-f=argument 4" "-o=app.conf:3"]`
+	if s := fmt.Sprintf("%q", got); s != want {
+		t.Errorf("origins = %s, want %s", s, want)
+	}
+}
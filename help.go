@@ -0,0 +1,193 @@
+package oldflag
+
+import (
+	"strings"
+	"unicode"
+)
+
+// maxHelpColumn is the widest column PrintDefaults starts usage messages
+// at; flags whose names do not fit before it have their message on the
+// next line.
+const maxHelpColumn = 32
+
+// A helpEntry is an item listed by PrintDefaults: a flag or an operand.
+type helpEntry struct {
+	heading string   // if not empty, the entry starts a group with this heading
+	names   string   // names and argument, such as "-x, --extra int"
+	usage   string   // usage message, with its annotations
+	choices []string // described choices, listed below the message
+	descs   []string // descriptions of the choices
+}
+
+// helpColumn returns the column usage messages start at for entries in
+// width columns: two past the widest names that fit.
+func helpColumn(entries []helpEntry, width int) int {
+	limit := maxHelpColumn
+	if limit > width/2 {
+		limit = width / 2
+	}
+	col := 0
+	for _, e := range entries {
+		if n := 2 + displayWidth(e.names) + 2; n > col && n <= limit {
+			col = n
+		}
+	}
+	if col == 0 {
+		col = limit
+	}
+	return col
+}
+
+// formatHelp formats entries in two columns, the names and the usage
+// messages, with the messages wrapped to width with a hanging indent.
+func formatHelp(entries []helpEntry, width int) string {
+	col := helpColumn(entries, width)
+	indent := strings.Repeat(" ", col)
+	var b strings.Builder
+	for i, e := range entries {
+		if e.heading != "" {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			b.WriteString(e.heading + ":\n")
+		}
+		b.WriteString("  " + e.names)
+		n := 2 + displayWidth(e.names)
+		if e.usage != "" {
+			if n+2 > col {
+				b.WriteString("\n" + indent)
+			} else {
+				b.WriteString(strings.Repeat(" ", col-n))
+			}
+			b.WriteString(wrapText(e.usage, col, width))
+		}
+		b.WriteString("\n")
+
+		// Described choices are listed below the message, in a column of
+		// their own.
+		nameWidth := 0
+		for _, name := range e.choices {
+			if n := displayWidth(name); n > nameWidth {
+				nameWidth = n
+			}
+		}
+		for j, name := range e.choices {
+			desc := e.descs[j]
+			b.WriteString(indent + "  " + name)
+			if desc != "" {
+				b.WriteString(strings.Repeat(" ", nameWidth-displayWidth(name)+2))
+				b.WriteString(wrapText(desc, col+2+nameWidth+2, width))
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// wrapText wraps text, which starts at column indent, to width columns,
+// indenting continuation lines to indent. Line breaks in text are kept.
+func wrapText(text string, indent, width int) string {
+	pad := "\n" + strings.Repeat(" ", indent)
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		words := strings.Fields(line)
+		if len(words) == 0 {
+			lines[i] = ""
+			continue
+		}
+		s := wrapWords("", words, width-indent)
+		lines[i] = strings.ReplaceAll(strings.TrimSuffix(s, "\n"), "\n", pad)
+	}
+	return strings.Join(lines, pad)
+}
+
+// displayWidth returns the number of columns s takes up in a terminal.
+func displayWidth(s string) int {
+	n := 0
+	for _, r := range s {
+		n += runeWidth(r)
+	}
+	return n
+}
+
+// runeWidth returns the number of columns r takes up in a terminal: none
+// for combining marks and control characters, two for wide characters,
+// such as those of East Asian scripts, and one for the others.
+func runeWidth(r rune) int {
+	switch {
+	case r == 0, unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf, unicode.Cc):
+		return 0
+	case isWide(r):
+		return 2
+	}
+	return 1
+}
+
+// wideRanges are the ranges of East Asian wide and fullwidth characters,
+// and of emoji, which terminals show in two columns.
+var wideRanges = [][2]rune{
+	{0x1100, 0x115F},   // Hangul Jamo
+	{0x231A, 0x231B},   // watch, hourglass
+	{0x2329, 0x232A},   // angle brackets
+	{0x23E9, 0x23EC},   // media controls
+	{0x23F0, 0x23F0},   // alarm clock
+	{0x23F3, 0x23F3},   // hourglass with flowing sand
+	{0x25FD, 0x25FE},   // small squares
+	{0x2614, 0x2615},   // umbrella, hot beverage
+	{0x2648, 0x2653},   // zodiac
+	{0x26AA, 0x26AB},   // circles
+	{0x26BD, 0x26BE},   // balls
+	{0x2705, 0x2705},   // check mark
+	{0x270A, 0x270B},   // hands
+	{0x2728, 0x2728},   // sparkles
+	{0x274C, 0x274C},   // cross mark
+	{0x2753, 0x2755},   // question and exclamation marks
+	{0x2795, 0x2797},   // heavy plus, minus, division
+	{0x2B1B, 0x2B1C},   // large squares
+	{0x2B50, 0x2B50},   // star
+	{0x2E80, 0x303E},   // CJK radicals, punctuation
+	{0x3041, 0x33FF},   // kana, CJK compatibility
+	{0x3400, 0x4DBF},   // CJK extension A
+	{0x4E00, 0x9FFF},   // CJK unified ideographs
+	{0xA000, 0xA4CF},   // Yi
+	{0xA960, 0xA97F},   // Hangul Jamo extended A
+	{0xAC00, 0xD7A3},   // Hangul syllables
+	{0xF900, 0xFAFF},   // CJK compatibility ideographs
+	{0xFE10, 0xFE19},   // vertical forms
+	{0xFE30, 0xFE6F},   // CJK compatibility forms, small forms
+	{0xFF00, 0xFF60},   // fullwidth forms
+	{0xFFE0, 0xFFE6},   // fullwidth signs
+	{0x16FE0, 0x18CFF}, // Tangut, Khitan
+	{0x1B000, 0x1B2FF}, // kana supplement, Nushu
+	{0x1F004, 0x1F004}, // mahjong tile
+	{0x1F0CF, 0x1F0CF}, // playing card
+	{0x1F18E, 0x1F18E}, // AB button
+	{0x1F191, 0x1F19A}, // squared words
+	{0x1F200, 0x1F2FF}, // enclosed ideographs
+	{0x1F300, 0x1F64F}, // pictographs, emoticons
+	{0x1F680, 0x1F6FF}, // transport and map symbols
+	{0x1F7E0, 0x1F7EB}, // large circles and squares
+	{0x1F90C, 0x1F9FF}, // supplemental pictographs
+	{0x1FA70, 0x1FAFF}, // pictographs extended A
+	{0x20000, 0x3FFFD}, // CJK extensions B and beyond
+}
+
+// isWide reports whether r is shown in two columns.
+func isWide(r rune) bool {
+	if r < wideRanges[0][0] {
+		return false
+	}
+	lo, hi := 0, len(wideRanges)
+	for lo < hi {
+		m := int(uint(lo+hi) >> 1)
+		switch {
+		case r < wideRanges[m][0]:
+			hi = m
+		case r > wideRanges[m][1]:
+			lo = m + 1
+		default:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,236 @@
+package oldflag
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A ManPage holds what goes into a manual page besides the flags. Only
+// Title is required; the other fields are left out of the page if empty.
+type ManPage struct {
+	Name        string       // name of the page; the name of the flag set if empty
+	Section     string       // manual section; 1 if empty
+	Date        string       // date of the last change, such as 2024-05-01
+	Source      string       // package the program is part of, such as "tool 1.2"
+	Manual      string       // title of the manual, such as "User Commands"
+	Title       string       // one-line description, for the NAME section
+	Operands    string       // operands in the synopsis; as in FlagSet.Synopsis if empty
+	Description string       // paragraphs separated by blank lines; the set's Description if empty
+	Examples    []ManExample // shown in the EXAMPLES section
+	SeeAlso     []string     // related pages, such as "ls(1)"
+}
+
+// A ManExample is an example shown in a manual page.
+type ManExample struct {
+	Description string // what the example does
+	Command     string // the command line, shown literally
+}
+
+// WriteMan writes to w a manual page for the program whose flags are in the
+// set, in the man(7) roff format. The SYNOPSIS section lists the flags, with
+// the boolean flags with rune names clustered, as in
+//	tool [-av] [-c count] [--color[=when]] file ...
+// and the OPTIONS section describes each flag with its usage message, its
+// argument name as given by UnquoteUsage, and its default value, with the
+// flags of each Group in a subsection of their own. Flags bound to
+// environment variables are also listed in the ENVIRONMENT section.
+//
+// Since the page depends only on the flags, it can be kept up to date with
+// go generate, by having the program write it when run with a hidden flag:
+//	//go:generate sh -c "go run . --man-page > tool.1"
+func (f *FlagSet) WriteMan(w io.Writer, page *ManPage) error {
+	name := page.Name
+	if name == "" {
+		name = f.programName()
+	}
+	if name == "" {
+		return fmt.Errorf("flag set has no name for the manual page")
+	}
+	section := page.Section
+	if section == "" {
+		section = "1"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, ".TH %s %s %s %s %s\n", manQuote(strings.ToUpper(name)), manQuote(section),
+		manQuote(page.Date), manQuote(page.Source), manQuote(page.Manual))
+
+	fmt.Fprintf(&b, ".SH NAME\n%s \\- %s\n", manEscape(name), manEscape(page.Title))
+
+	fmt.Fprintf(&b, ".SH SYNOPSIS\n.B %s\n", manEscape(name))
+	for _, item := range synopsis(f) {
+		fmt.Fprintf(&b, "%s\n", item.man())
+	}
+	operands := page.Operands
+	if operands == "" {
+		operands = f.argsUsage()
+	}
+	if operands != "" {
+		fmt.Fprintf(&b, "\\fI%s\\fR\n", manEscape(operands))
+	}
+
+	description := page.Description
+	if description == "" {
+		description = f.Description
+	}
+	if description != "" {
+		fmt.Fprintf(&b, ".SH DESCRIPTION\n")
+		writeManText(&b, description)
+	}
+
+	if len(f.flags) > 0 {
+		fmt.Fprintf(&b, ".SH OPTIONS\n")
+	}
+	for _, group := range f.groups() {
+		if group.name != "" {
+			fmt.Fprintf(&b, ".SS %s\n", manQuote(group.name))
+		}
+		for _, flag := range group.flags {
+			fmt.Fprintf(&b, ".TP\n%s\n", manFlag(flag))
+			_, usage := UnquoteUsage(flag)
+			text := manEscape(usage)
+			notes := f.annotations(flag, func(s string, name bool) string {
+				if name {
+					return "\\fB" + manEscape(s) + "\\fR"
+				}
+				return manEscape(s)
+			})
+			if len(notes) > 0 {
+				text += " " + strings.Join(notes, " ")
+			}
+			names, descs := describedChoices(flag)
+			fmt.Fprintf(&b, "%s\n", strings.ReplaceAll(text, "\n", "\n.br\n"))
+			if descs != nil {
+				fmt.Fprintf(&b, ".RS\n")
+				for i, name := range names {
+					fmt.Fprintf(&b, ".TP\n.B %s\n%s\n", manEscape(name), manEscape(descs[i]))
+				}
+				fmt.Fprintf(&b, ".RE\n")
+			}
+		}
+	}
+
+	if len(f.operands) > 0 {
+		fmt.Fprintf(&b, ".SH OPERANDS\n")
+		for _, op := range f.operands {
+			fmt.Fprintf(&b, ".TP\n\\fI%s\\fR\n%s\n", manEscape(op.synopsis()), manEscape(op.Usage))
+		}
+	}
+
+	var envs []string
+	for _, flag := range sortFlags(f.flags) {
+		if env := f.envName(flag); env != "" {
+			envs = append(envs, fmt.Sprintf(".TP\n.B %s\nSets \\fB%s\\fR.\n", manEscape(env), manEscape(flag.spelling())))
+		}
+	}
+	if len(envs) > 0 {
+		fmt.Fprintf(&b, ".SH ENVIRONMENT\n%s", strings.Join(envs, ""))
+	}
+
+	if len(page.Examples) > 0 {
+		fmt.Fprintf(&b, ".SH EXAMPLES\n")
+		for i, ex := range page.Examples {
+			if i > 0 {
+				fmt.Fprintf(&b, ".PP\n")
+			}
+			if ex.Description != "" {
+				fmt.Fprintf(&b, "%s\n", manEscape(ex.Description))
+			}
+			fmt.Fprintf(&b, ".PP\n.RS\n.nf\n%s\n.fi\n.RE\n", manEscape(ex.Command))
+		}
+	}
+
+	if len(page.SeeAlso) > 0 {
+		refs := make([]string, len(page.SeeAlso))
+		for i, ref := range page.SeeAlso {
+			refs[i] = manRef(ref)
+		}
+		fmt.Fprintf(&b, ".SH SEE ALSO\n%s\n", strings.Join(refs, ",\n"))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteMan writes to w a manual page for the program whose flags are the
+// command-line flags. See FlagSet.WriteMan for details.
+func WriteMan(w io.Writer, page *ManPage) error {
+	return CommandLine.WriteMan(w, page)
+}
+
+// man returns the item in roff, with the flag in bold and the argument in
+// italics.
+func (item synopsisItem) man() string {
+	return item.format(func(s string, arg bool) string {
+		if arg {
+			return "\\fI" + manEscape(s) + "\\fR"
+		}
+		return "\\fB" + manEscape(s) + "\\fR"
+	})
+}
+
+// manFlag returns the names of flag, and its argument, in roff, as in the
+// first line of its entry in PrintDefaults.
+func manFlag(flag *Flag) string {
+	var names []string
+	if flag.Name != 0 {
+		names = append(names, "\\fB"+manEscape("-"+string(flag.Name))+"\\fR")
+	}
+	if flag.Long != "" {
+		names = append(names, "\\fB"+manEscape("--"+flag.Long)+"\\fR")
+	}
+	s := strings.Join(names, ", ")
+	name, _ := UnquoteUsage(flag)
+	if flag.Optional && name == "" {
+		name = "value"
+	}
+	if name == "" {
+		return s
+	}
+	arg := "\\fI" + manEscape(name) + "\\fR"
+	switch {
+	case flag.Optional && flag.Long == "":
+		return s + "[" + arg + "]"
+	case flag.Optional:
+		return s + "[=" + arg + "]"
+	}
+	return s + " " + arg
+}
+
+// manRef returns a reference to another page, such as ls(1), in roff, with
+// the name in bold.
+func manRef(ref string) string {
+	if i := strings.LastIndexByte(ref, '('); i > 0 {
+		return "\\fB" + manEscape(ref[:i]) + "\\fR" + manEscape(ref[i:])
+	}
+	return "\\fB" + manEscape(ref) + "\\fR"
+}
+
+// writeManText writes text, in paragraphs separated by blank lines, in roff.
+func writeManText(b *strings.Builder, text string) {
+	for i, para := range strings.Split(strings.TrimSpace(text), "\n\n") {
+		if i > 0 {
+			fmt.Fprintf(b, ".PP\n")
+		}
+		fmt.Fprintf(b, "%s\n", manEscape(strings.TrimSpace(para)))
+	}
+}
+
+// manEscape escapes s for roff: backslashes and minus signs, and periods
+// and apostrophes at the start of a line, which would begin a request.
+func manEscape(s string) string {
+	s = strings.NewReplacer(`\`, `\e`, "-", `\-`).Replace(s)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			lines[i] = `\&` + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// manQuote returns s escaped and quoted as an argument of a roff request.
+func manQuote(s string) string {
+	return `"` + strings.ReplaceAll(manEscape(s), `"`, `""`) + `"`
+}
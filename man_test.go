@@ -0,0 +1,77 @@
+package oldflag
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteMan(t *testing.T) {
+	f := NewFlagSet("tool", ContinueOnError)
+	f.Bool('a', false, "all\n.also hidden files")
+	f.StringLong('o', "output", "-", "write to `file`")
+	f.BoolLong(0, "dry-run", false, "do nothing")
+	f.Lookup('o').Env = "TOOL_OUTPUT"
+	f.Lookup('a').Group = "Filtering"
+	f.StringOperand("src", "the file to read")
+	f.Description = "Tool copies 'src' to a file.\n\nIt does it\\well."
+	page := &ManPage{
+		Date:     "2024-05-01",
+		Source:   `tool "1.0"`,
+		Title:    "copy files",
+		Examples: []ManExample{{"Copy to standard output:", "tool -o - src"}},
+		SeeAlso:  []string{"cp(1)", "cat"},
+	}
+	var b strings.Builder
+	if err := f.WriteMan(&b, page); err != nil {
+		t.Fatal(err)
+	}
+	want := `.TH "TOOL" "1" "2024\-05\-01" "tool ""1.0""" ""
+.SH NAME
+tool \- copy files
+.SH SYNOPSIS
+.B tool
+[\fB\-a\fR]
+[\fB\-\-dry\-run\fR]
+[\fB\-o\fR \fIfile\fR]
+\fIsrc\fR
+.SH DESCRIPTION
+Tool copies 'src' to a file.
+.PP
+It does it\ewell.
+.SH OPTIONS
+.TP
+\fB\-\-dry\-run\fR
+do nothing
+.TP
+\fB\-o\fR, \fB\-\-output\fR \fIfile\fR
+write to file (default "\-") (env \fB$TOOL_OUTPUT\fR)
+.SS "Filtering"
+.TP
+\fB\-a\fR
+all
+.br
+\&.also hidden files
+.SH OPERANDS
+.TP
+\fIsrc\fR
+the file to read
+.SH ENVIRONMENT
+.TP
+.B TOOL_OUTPUT
+Sets \fB\-o\fR.
+.SH EXAMPLES
+Copy to standard output:
+.PP
+.RS
+.nf
+tool \-o \- src
+.fi
+.RE
+.SH SEE ALSO
+\fBcp\fR(1),
+\fBcat\fR
+`
+	if got := b.String(); got != want {
+		t.Errorf("WriteMan:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,311 @@
+package oldflag
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// optional interface to indicate repeatable flags whose value is a map,
+// set by occurrences of the form key=value
+type mapFlag interface {
+	repeatableFlag
+	hasKey(key string) bool
+}
+
+// A KeyPolicy tells what a map flag does with a key given more than once.
+type KeyPolicy int
+
+// These constants cause a map flag to behave as described if a key is
+// given more than once from the same source.
+const (
+	KeepLast        KeyPolicy = iota // The last value is kept.
+	KeepFirst                        // The first value is kept.
+	RejectDuplicate                  // Parsing fails.
+)
+
+var errMapSyntax = errors.New("expected key=value")
+
+// splitPair splits s, of the form key=value, into its key and value.
+func splitPair(s string) (key, value string, err error) {
+	i := strings.IndexByte(s, '=')
+	if i <= 0 {
+		return "", "", errMapSyntax
+	}
+	return s[:i], s[i+1:], nil
+}
+
+// formatMap formats the pairs of a map value as [a=1 b=2], sorted by key.
+func formatMap(keys []string, value func(key string) string) string {
+	sort.Strings(keys)
+	return formatSlice(len(keys), func(i int) string { return keys[i] + "=" + value(keys[i]) })
+}
+
+// -- map[string]string Value
+type stringMapValue map[string]string
+
+func newStringMapValue(val map[string]string, p *map[string]string) *stringMapValue {
+	*p = make(map[string]string, len(val))
+	for k, v := range val {
+		(*p)[k] = v
+	}
+	return (*stringMapValue)(p)
+}
+
+func (m *stringMapValue) Set(s string) error {
+	k, v, err := splitPair(s)
+	if err != nil {
+		return err
+	}
+	if *m == nil {
+		*m = make(map[string]string)
+	}
+	(*m)[k] = v
+	return nil
+}
+
+func (m *stringMapValue) Reset() { *m = make(map[string]string) }
+
+func (m *stringMapValue) IsRepeatable() bool { return true }
+
+func (m *stringMapValue) hasKey(key string) bool {
+	_, ok := (*m)[key]
+	return ok
+}
+
+func (m *stringMapValue) Get() interface{} { return map[string]string(*m) }
+
+func (m *stringMapValue) String() string {
+	var keys []string
+	for k := range *m {
+		keys = append(keys, k)
+	}
+	return formatMap(keys, func(k string) string { return (*m)[k] })
+}
+
+// -- map[string]int Value
+type intMapValue map[string]int
+
+func newIntMapValue(val map[string]int, p *map[string]int) *intMapValue {
+	*p = make(map[string]int, len(val))
+	for k, v := range val {
+		(*p)[k] = v
+	}
+	return (*intMapValue)(p)
+}
+
+func (m *intMapValue) Set(s string) error {
+	k, v, err := splitPair(s)
+	if err != nil {
+		return err
+	}
+	n, err := strconv.ParseInt(v, 0, strconv.IntSize)
+	if err != nil {
+		return numError(err)
+	}
+	if *m == nil {
+		*m = make(map[string]int)
+	}
+	(*m)[k] = int(n)
+	return nil
+}
+
+func (m *intMapValue) Reset() { *m = make(map[string]int) }
+
+func (m *intMapValue) IsRepeatable() bool { return true }
+
+func (m *intMapValue) hasKey(key string) bool {
+	_, ok := (*m)[key]
+	return ok
+}
+
+func (m *intMapValue) Get() interface{} { return map[string]int(*m) }
+
+func (m *intMapValue) String() string {
+	var keys []string
+	for k := range *m {
+		keys = append(keys, k)
+	}
+	return formatMap(keys, func(k string) string { return strconv.Itoa((*m)[k]) })
+}
+
+// -- map[string]Value Value
+type valueMapValue struct {
+	p        *map[string]Value
+	newValue func() Value
+}
+
+func (m *valueMapValue) Set(s string) error {
+	k, v, err := splitPair(s)
+	if err != nil {
+		return err
+	}
+	val := m.newValue()
+	if err := val.Set(v); err != nil {
+		return err
+	}
+	if *m.p == nil {
+		*m.p = make(map[string]Value)
+	}
+	(*m.p)[k] = val
+	return nil
+}
+
+func (m *valueMapValue) Reset() { *m.p = make(map[string]Value) }
+
+func (m *valueMapValue) IsRepeatable() bool { return true }
+
+func (m *valueMapValue) hasKey(key string) bool {
+	_, ok := (*m.p)[key]
+	return ok
+}
+
+func (m *valueMapValue) Get() interface{} { return *m.p }
+
+func (m *valueMapValue) String() string {
+	if m.p == nil {
+		return "[]"
+	}
+	var keys []string
+	for k := range *m.p {
+		keys = append(keys, k)
+	}
+	return formatMap(keys, func(k string) string { return (*m.p)[k].String() })
+}
+
+// StringMapVar defines a repeatable key=value flag with specified name, default value, and usage string.
+// The argument p points to a map[string]string variable in which to store the values of the flag.
+func (f *FlagSet) StringMapVar(p *map[string]string, name rune, value map[string]string, usage string) {
+	f.Var(newStringMapValue(value, p), name, usage)
+}
+
+// StringMapVar defines a repeatable key=value flag with specified name, default value, and usage string.
+// The argument p points to a map[string]string variable in which to store the values of the flag.
+func StringMapVar(p *map[string]string, name rune, value map[string]string, usage string) {
+	CommandLine.Var(newStringMapValue(value, p), name, usage)
+}
+
+// StringMap defines a repeatable key=value flag with specified name, default value, and usage string.
+// The return value is the address of a map[string]string variable that stores the values of the flag.
+func (f *FlagSet) StringMap(name rune, value map[string]string, usage string) *map[string]string {
+	p := new(map[string]string)
+	f.StringMapVar(p, name, value, usage)
+	return p
+}
+
+// StringMap defines a repeatable key=value flag with specified name, default value, and usage string.
+// The return value is the address of a map[string]string variable that stores the values of the flag.
+func StringMap(name rune, value map[string]string, usage string) *map[string]string {
+	return CommandLine.StringMap(name, value, usage)
+}
+
+// StringMapVarLong defines a repeatable key=value flag with specified name, long name, default value, and usage string.
+// The argument p points to a map[string]string variable in which to store the values of the flag.
+func (f *FlagSet) StringMapVarLong(p *map[string]string, name rune, long string, value map[string]string, usage string) {
+	f.VarLong(newStringMapValue(value, p), name, long, usage)
+}
+
+// StringMapVarLong defines a repeatable key=value flag with specified name, long name, default value, and usage string.
+// The argument p points to a map[string]string variable in which to store the values of the flag.
+func StringMapVarLong(p *map[string]string, name rune, long string, value map[string]string, usage string) {
+	CommandLine.VarLong(newStringMapValue(value, p), name, long, usage)
+}
+
+// StringMapLong defines a repeatable key=value flag with specified name, long name, default value, and usage string.
+// The return value is the address of a map[string]string variable that stores the values of the flag.
+func (f *FlagSet) StringMapLong(name rune, long string, value map[string]string, usage string) *map[string]string {
+	p := new(map[string]string)
+	f.StringMapVarLong(p, name, long, value, usage)
+	return p
+}
+
+// StringMapLong defines a repeatable key=value flag with specified name, long name, default value, and usage string.
+// The return value is the address of a map[string]string variable that stores the values of the flag.
+func StringMapLong(name rune, long string, value map[string]string, usage string) *map[string]string {
+	return CommandLine.StringMapLong(name, long, value, usage)
+}
+
+// IntMapVar defines a repeatable key=int flag with specified name, default value, and usage string.
+// The argument p points to a map[string]int variable in which to store the values of the flag.
+func (f *FlagSet) IntMapVar(p *map[string]int, name rune, value map[string]int, usage string) {
+	f.Var(newIntMapValue(value, p), name, usage)
+}
+
+// IntMapVar defines a repeatable key=int flag with specified name, default value, and usage string.
+// The argument p points to a map[string]int variable in which to store the values of the flag.
+func IntMapVar(p *map[string]int, name rune, value map[string]int, usage string) {
+	CommandLine.Var(newIntMapValue(value, p), name, usage)
+}
+
+// IntMap defines a repeatable key=int flag with specified name, default value, and usage string.
+// The return value is the address of a map[string]int variable that stores the values of the flag.
+func (f *FlagSet) IntMap(name rune, value map[string]int, usage string) *map[string]int {
+	p := new(map[string]int)
+	f.IntMapVar(p, name, value, usage)
+	return p
+}
+
+// IntMap defines a repeatable key=int flag with specified name, default value, and usage string.
+// The return value is the address of a map[string]int variable that stores the values of the flag.
+func IntMap(name rune, value map[string]int, usage string) *map[string]int {
+	return CommandLine.IntMap(name, value, usage)
+}
+
+// IntMapVarLong defines a repeatable key=int flag with specified name, long name, default value, and usage string.
+// The argument p points to a map[string]int variable in which to store the values of the flag.
+func (f *FlagSet) IntMapVarLong(p *map[string]int, name rune, long string, value map[string]int, usage string) {
+	f.VarLong(newIntMapValue(value, p), name, long, usage)
+}
+
+// IntMapVarLong defines a repeatable key=int flag with specified name, long name, default value, and usage string.
+// The argument p points to a map[string]int variable in which to store the values of the flag.
+func IntMapVarLong(p *map[string]int, name rune, long string, value map[string]int, usage string) {
+	CommandLine.VarLong(newIntMapValue(value, p), name, long, usage)
+}
+
+// IntMapLong defines a repeatable key=int flag with specified name, long name, default value, and usage string.
+// The return value is the address of a map[string]int variable that stores the values of the flag.
+func (f *FlagSet) IntMapLong(name rune, long string, value map[string]int, usage string) *map[string]int {
+	p := new(map[string]int)
+	f.IntMapVarLong(p, name, long, value, usage)
+	return p
+}
+
+// IntMapLong defines a repeatable key=int flag with specified name, long name, default value, and usage string.
+// The return value is the address of a map[string]int variable that stores the values of the flag.
+func IntMapLong(name rune, long string, value map[string]int, usage string) *map[string]int {
+	return CommandLine.IntMapLong(name, long, value, usage)
+}
+
+// MapVar defines a repeatable key=value flag with specified name and usage string.
+// Each occurrence of the flag sets a new Value, returned by newValue, to the
+// part after the =, and stores it in the map p points to, which holds the
+// default values, under the part before.
+func (f *FlagSet) MapVar(p *map[string]Value, name rune, newValue func() Value, usage string) {
+	f.Var(&valueMapValue{p, newValue}, name, usage)
+}
+
+// MapVar defines a repeatable key=value flag with specified name and usage string.
+// Each occurrence of the flag sets a new Value, returned by newValue, to the
+// part after the =, and stores it in the map p points to, which holds the
+// default values, under the part before.
+func MapVar(p *map[string]Value, name rune, newValue func() Value, usage string) {
+	CommandLine.Var(&valueMapValue{p, newValue}, name, usage)
+}
+
+// MapVarLong defines a repeatable key=value flag with specified name, long name and usage string.
+// Each occurrence of the flag sets a new Value, returned by newValue, to the
+// part after the =, and stores it in the map p points to, which holds the
+// default values, under the part before.
+func (f *FlagSet) MapVarLong(p *map[string]Value, name rune, long string, newValue func() Value, usage string) {
+	f.VarLong(&valueMapValue{p, newValue}, name, long, usage)
+}
+
+// MapVarLong defines a repeatable key=value flag with specified name, long name and usage string.
+// Each occurrence of the flag sets a new Value, returned by newValue, to the
+// part after the =, and stores it in the map p points to, which holds the
+// default values, under the part before.
+func MapVarLong(p *map[string]Value, name rune, long string, newValue func() Value, usage string) {
+	CommandLine.VarLong(&valueMapValue{p, newValue}, name, long, usage)
+}
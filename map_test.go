@@ -0,0 +1,35 @@
+package oldflag
+
+import (
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestStringMap(t *testing.T) {
+	tests := []struct {
+		policy KeyPolicy
+		args   []string
+		want   string // the map, or the error
+	}{
+		{KeepLast, nil, "[CC=gcc]"},
+		{KeepLast, []string{"-DA=1", "-D", "B=", "-DA=3"}, "[A=3 B=]"},
+		{KeepFirst, []string{"-DA=1", "-DA=3"}, "[A=1]"},
+		{RejectDuplicate, []string{"-DA=1", "-DA=3"}, `invalid value "A=3" for flag -D: duplicate key "A"`},
+		{KeepLast, []string{"-DA"}, `invalid value "A" for flag -D: expected key=value`},
+		{KeepLast, []string{"-D", "=1"}, `invalid value "=1" for flag -D: expected key=value`},
+	}
+	for _, tt := range tests {
+		f := NewFlagSet("test", ContinueOnError)
+		f.SetOutput(io.Discard)
+		f.StringMap('D', map[string]string{"CC": "gcc"}, "")
+		f.Lookup('D').Duplicates = tt.policy
+		got := fmt.Sprint(f.Parse(tt.args))
+		if got == "<nil>" {
+			got = f.Lookup('D').Value.String()
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) with policy %d: got %s, want %s", tt.args, tt.policy, got, tt.want)
+		}
+	}
+}
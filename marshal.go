@@ -0,0 +1,135 @@
+package oldflag
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Marshal returns an argument list that, given to Parse of a set with the
+// same flags, reproduces the values of the flags of the set. It holds the
+// flags whose value differs from the default, and the required ones, in
+// lexicographical order, followed by the operands left by the last Parse,
+// after "--" if one of them looks like a flag. Boolean flags set to true
+// are clustered, as in -av, and a repeatable flag is given once for each
+// of its values. A flag with a rune name is given by it, its value in the
+// next argument, and a flag with only a long name as --long=value, so that
+// values are taken as they are, whatever they hold. To log the argument
+// list as a shell command, see QuoteArgs.
+func (f *FlagSet) Marshal() []string {
+	var args []string
+	cluster := "-"
+	for _, flag := range sortFlags(f.flags) {
+		value := flag.Value.String()
+		if value == flag.DefValue && !flag.Required {
+			continue
+		}
+		switch {
+		case flag.Optional:
+			if flag.Name != 0 {
+				args = append(args, "-"+string(flag.Name)+"="+value)
+			} else {
+				args = append(args, "--"+flag.Long+"="+value)
+			}
+		case flag.kind() == kindBool:
+			if _, ok := flag.Value.(*countValue); !ok && value == "true" && flag.Name != 0 {
+				cluster += string(flag.Name)
+			} else {
+				args = append(args, flag.spelling()+"="+value)
+			}
+		default:
+			for _, v := range marshalValues(flag.Value, value) {
+				if flag.Name != 0 {
+					args = append(args, "-"+string(flag.Name), v)
+				} else {
+					args = append(args, "--"+flag.Long+"="+v)
+				}
+			}
+		}
+	}
+	if cluster != "-" {
+		args = append([]string{cluster}, args...)
+	}
+	for _, arg := range f.Args() {
+		if strings.HasPrefix(arg, "-") {
+			args = append(args, "--")
+			break
+		}
+	}
+	return append(args, f.Args()...)
+}
+
+// Marshal returns an argument list that reproduces the values of the
+// command-line flags. See FlagSet.Marshal for details.
+func Marshal() []string {
+	return CommandLine.Marshal()
+}
+
+// MarshalStruct returns an argument list that, given to Parse of a set
+// with the flags defined by Bind for the struct v points to, reproduces the
+// values of its fields, as FlagSet.Marshal does.
+func MarshalStruct(v interface{}) ([]string, error) {
+	p := reflect.ValueOf(v)
+	if p.Kind() != reflect.Ptr || p.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot marshal %T: not a pointer to a struct", v)
+	}
+	// Bind a copy, so that its defaults are recorded without changing v,
+	// and then give it the values of v.
+	c := reflect.New(p.Elem().Type())
+	f := NewFlagSet("", ContinueOnError)
+	if err := f.Bind(c.Interface()); err != nil {
+		return nil, err
+	}
+	c.Elem().Set(p.Elem())
+	return f.Marshal(), nil
+}
+
+// marshalValues returns the values that, set in turn, give value its
+// current state, whose String is s: one for each element of a repeatable
+// flag, or s itself.
+func marshalValues(value Value, s string) []string {
+	if rv, ok := value.(repeatableFlag); !ok || !rv.IsRepeatable() {
+		return []string{s}
+	}
+	g, ok := value.(Getter)
+	if !ok {
+		return []string{s}
+	}
+	var values []string
+	v := reflect.ValueOf(g.Get())
+	switch v.Kind() {
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			values = append(values, fmt.Sprint(v.Index(i).Interface()))
+		}
+	case reflect.Map:
+		for _, k := range v.MapKeys() {
+			values = append(values, fmt.Sprintf("%v=%v", k.Interface(), v.MapIndex(k).Interface()))
+		}
+		sort.Strings(values)
+	default:
+		return []string{s}
+	}
+	return values
+}
+
+// QuoteArgs returns args as a command line for the POSIX shell, quoting
+// the arguments that need it, as for logging how to reproduce a run:
+//	log.Printf("running %s %s", name, flag.QuoteArgs(fs.Marshal()))
+func QuoteArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = arg
+		if arg == "" || strings.IndexFunc(arg, needsQuote) >= 0 {
+			quoted[i] = shellQuote(arg)
+		}
+	}
+	return strings.Join(quoted, " ")
+}
+
+// needsQuote reports whether r is special to the POSIX shell in an
+// unquoted word.
+func needsQuote(r rune) bool {
+	return !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || strings.ContainsRune("-_.,/+=:@%", r))
+}
@@ -0,0 +1,71 @@
+package oldflag
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newMarshalFlagSet() *FlagSet {
+	f := NewFlagSet("test", ContinueOnError)
+	f.Bool('a', false, "")
+	f.Bool('t', true, "")
+	f.Count('v', 0, "")
+	f.StringLong('o', "output", "", "")
+	f.StringLong(0, "name", "", "")
+	f.IntLong('g', "debug", 0, "")
+	f.Lookup('g').Optional = true
+	f.StringSlice('I', []string{"d"}, "")
+	f.StringMap('D', nil, "")
+	f.Duration('d', time.Second, "")
+	return f
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	f := newMarshalFlagSet()
+	args := []string{"-avvt=false", "-o", "a b", "--name=-x'y", "-g=7", "-Ia", "-I", "", "-DB=2", "-DA=", "x", "--", "-y"}
+	if err := f.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	marshaled := f.Marshal()
+	want := []string{"-a", "-D", "A=", "-D", "B=2", "-I", "a", "-I", "", "-g=7", "--name=-x'y", "-o", "a b", "-t=false", "-v=2", "--", "x", "--", "-y"}
+	if !reflect.DeepEqual(marshaled, want) {
+		t.Errorf("Marshal() = %q, want %q", marshaled, want)
+	}
+
+	g := newMarshalFlagSet()
+	if err := g.Parse(marshaled); err != nil {
+		t.Fatal(err)
+	}
+	f.VisitAll(func(flag *Flag) {
+		if got := g.Lookup(flag.Name); flag.Name != 0 && got.Value.String() != flag.Value.String() {
+			t.Errorf("-%c = %s after the round trip, want %s", flag.Name, got.Value, flag.Value)
+		}
+	})
+	if !reflect.DeepEqual(g.Args(), f.Args()) {
+		t.Errorf("Args() = %q after the round trip, want %q", g.Args(), f.Args())
+	}
+
+	want = []string{"-a", "-D", "A=", "-D", "B=2", "-I", "a", "-I", "''", "-g=7", `'--name=-x'\''y'`, "-o", "'a b'", "-t=false", "-v=2", "--", "x", "--", "-y"}
+	if got := QuoteArgs(marshaled); got != strings.Join(want, " ") {
+		t.Errorf("QuoteArgs() = %s, want %s", got, strings.Join(want, " "))
+	}
+}
+
+func TestMarshalStruct(t *testing.T) {
+	config := struct {
+		Count   int           `flag:"c,count" default:"2"`
+		Verbose bool          `flag:"v"`
+		Timeout time.Duration `default:"5s"`
+		Include []string      `flag:"I"`
+	}{Count: 2, Verbose: true, Timeout: time.Minute, Include: []string{"a", "b"}}
+	args, err := MarshalStruct(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"-v", "-I", "a", "-I", "b", "--timeout=1m0s"}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("MarshalStruct() = %q, want %q", args, want)
+	}
+}
@@ -0,0 +1,165 @@
+package oldflag
+
+import "strings"
+
+// An Operand represents an operand, that is, a positional argument
+// following the flags, declared with OperandVar or one of the *Operand
+// functions. Parse fills the operands in the order they were declared,
+// after the flags.
+type Operand struct {
+	Name  string // name as it appears in the synopsis
+	Usage string // help message
+	Value Value  // value as set
+
+	// Optional, if true, lets the operand be omitted. Operands are filled
+	// from the left, so an optional operand is only given if all the
+	// required operands that follow it are too.
+	Optional bool
+
+	// Variadic, if true, lets the operand be given several times; it takes
+	// all the arguments that the other operands leave over, calling Set for
+	// each. Only one operand should be variadic.
+	Variadic bool
+}
+
+// synopsis returns the operand as it appears in a synopsis, such as
+// src ... or [dst].
+func (op *Operand) synopsis() string {
+	s := op.Name
+	if op.Variadic {
+		s += " ..."
+	}
+	if op.Optional {
+		s = "[" + s + "]"
+	}
+	return s
+}
+
+// OperandVar declares an operand with specified name and usage string,
+// following those declared before. The type and value of the operand are
+// represented by the first argument, of type Value, which typically holds
+// a user-defined implementation of Value. The returned Operand may be
+// changed to make it optional or variadic:
+//	flag.OperandVar(&files, "file", "files to read").Variadic = true
+func (f *FlagSet) OperandVar(value Value, name string, usage string) *Operand {
+	op := &Operand{Name: name, Usage: usage, Value: value}
+	f.operands = append(f.operands, op)
+	return op
+}
+
+// OperandVar declares a command-line operand with specified name and usage
+// string. See FlagSet.OperandVar for details.
+func OperandVar(value Value, name string, usage string) *Operand {
+	return CommandLine.OperandVar(value, name, usage)
+}
+
+// StringOperand declares a string operand with specified name and usage string.
+// The return value is the address of a string variable that stores the value of the operand.
+func (f *FlagSet) StringOperand(name string, usage string) *string {
+	p := new(string)
+	f.OperandVar(newStringValue("", p), name, usage)
+	return p
+}
+
+// StringOperand declares a string command-line operand with specified name and usage string.
+// The return value is the address of a string variable that stores the value of the operand.
+func StringOperand(name string, usage string) *string {
+	return CommandLine.StringOperand(name, usage)
+}
+
+// IntOperand declares an int operand with specified name and usage string.
+// The return value is the address of an int variable that stores the value of the operand.
+func (f *FlagSet) IntOperand(name string, usage string) *int {
+	p := new(int)
+	f.OperandVar(newIntValue(0, p), name, usage)
+	return p
+}
+
+// IntOperand declares an int command-line operand with specified name and usage string.
+// The return value is the address of an int variable that stores the value of the operand.
+func IntOperand(name string, usage string) *int {
+	return CommandLine.IntOperand(name, usage)
+}
+
+// StringsOperand declares a variadic string operand with specified name and usage string,
+// which must be given at least once.
+// The return value is the address of a []string variable that stores the values of the operand.
+func (f *FlagSet) StringsOperand(name string, usage string) *[]string {
+	p := new([]string)
+	f.OperandVar(newStringSliceValue(nil, p), name, usage).Variadic = true
+	return p
+}
+
+// StringsOperand declares a variadic string command-line operand with specified name and usage string,
+// which must be given at least once.
+// The return value is the address of a []string variable that stores the values of the operand.
+func StringsOperand(name string, usage string) *[]string {
+	return CommandLine.StringsOperand(name, usage)
+}
+
+// Operands returns the declared operands, in order.
+func (f *FlagSet) Operands() []*Operand {
+	return f.operands
+}
+
+// argsUsage returns the description of the operands in the synopsis:
+// ArgsUsage if it is set, or else one made from the declared operands.
+func (f *FlagSet) argsUsage() string {
+	if f.ArgsUsage != "" || f.operands == nil {
+		return f.ArgsUsage
+	}
+	s := make([]string, len(f.operands))
+	for i, op := range f.operands {
+		s[i] = op.synopsis()
+	}
+	return strings.Join(s, " ")
+}
+
+// parseOperands sets the declared operands from the arguments left after
+// the flags.
+func (f *FlagSet) parseOperands() error {
+	if f.operands == nil || f.completing {
+		return nil
+	}
+	extra := len(f.args)
+	for _, op := range f.operands {
+		if !op.Optional {
+			extra--
+		}
+	}
+
+	args := f.args
+	for _, op := range f.operands {
+		n := 0
+		if !op.Optional {
+			n = 1
+		}
+		if op.Optional && extra > 0 {
+			n++
+			extra--
+		}
+		if op.Variadic && extra > 0 {
+			n += extra
+			extra = 0
+		}
+		if n > len(args) {
+			n = len(args)
+		}
+		if n == 0 && !op.Optional {
+			return f.failf("missing operand: %s", op.Name)
+		}
+		if rv, ok := op.Value.(repeatableFlag); ok && rv.IsRepeatable() && n > 0 {
+			rv.Reset()
+		}
+		for _, arg := range args[:n] {
+			if err := op.Value.Set(arg); err != nil {
+				return f.failf("invalid value %q for operand %s: %v", arg, op.Name, err)
+			}
+		}
+		args = args[n:]
+	}
+	if len(args) > 0 {
+		return f.failf("extra operand %q", args[0])
+	}
+	return nil
+}
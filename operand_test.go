@@ -0,0 +1,66 @@
+package oldflag
+
+import (
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestOperands(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string // the operands, or the error
+	}{
+		{[]string{}, "missing operand: src"},
+		{[]string{"a"}, "missing operand: dst"},
+		{[]string{"a", "b"}, "[a] b"},
+		{[]string{"a", "b", "c"}, "[a b] c"},
+		{[]string{"-v", "--", "-a", "b"}, "[-a] b"},
+	}
+	for _, tt := range tests {
+		f := NewFlagSet("test", ContinueOnError)
+		f.SetOutput(io.Discard)
+		f.Bool('v', false, "")
+		src := f.StringsOperand("src", "")
+		dst := f.StringOperand("dst", "")
+		got := fmt.Sprint(f.Parse(tt.args))
+		if got == "<nil>" {
+			got = fmt.Sprintf("%v %s", *src, *dst)
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %s, want %s", tt.args, got, tt.want)
+		}
+	}
+
+	optionalTests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"7"}, "7 []"},
+		{[]string{"7", "a", "b"}, "7 [a b]"},
+		{[]string{"x"}, `invalid value "x" for operand count: parse error`},
+	}
+	for _, tt := range optionalTests {
+		f := NewFlagSet("test", ContinueOnError)
+		f.SetOutput(io.Discard)
+		count := f.IntOperand("count", "")
+		var files []string
+		op := f.OperandVar(newStringSliceValue(nil, &files), "file", "")
+		op.Optional, op.Variadic = true, true
+		got := fmt.Sprint(f.Parse(tt.args))
+		if got == "<nil>" {
+			got = fmt.Sprintf("%d %v", *count, files)
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %s, want %s", tt.args, got, tt.want)
+		}
+	}
+
+	f := NewFlagSet("test", ContinueOnError)
+	f.SetOutput(io.Discard)
+	f.StringOperand("dst", "")
+	want := `extra operand "b"`
+	if err := f.Parse([]string{"a", "b"}); err == nil || err.Error() != want {
+		t.Errorf("Parse with an extra operand: got %v, want %s", err, want)
+	}
+}
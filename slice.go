@@ -0,0 +1,323 @@
+package oldflag
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// optional interface to indicate flags that may be given more than once,
+// each occurrence adding to the value. Reset empties the value, so that
+// the first occurrence replaces the default, or the value taken from a
+// source of lower precedence, such as a configuration file.
+type repeatableFlag interface {
+	Value
+	IsRepeatable() bool
+	Reset()
+}
+
+// formatSlice formats the elements of a slice value as [a b c].
+func formatSlice(n int, elem func(i int) string) string {
+	s := make([]string, n)
+	for i := range s {
+		s[i] = elem(i)
+	}
+	return "[" + strings.Join(s, " ") + "]"
+}
+
+// -- []string Value
+type stringSliceValue []string
+
+func newStringSliceValue(val []string, p *[]string) *stringSliceValue {
+	*p = append([]string(nil), val...)
+	return (*stringSliceValue)(p)
+}
+
+func (s *stringSliceValue) Set(val string) error {
+	*s = append(*s, val)
+	return nil
+}
+
+func (s *stringSliceValue) Reset() { *s = nil }
+
+func (s *stringSliceValue) IsRepeatable() bool { return true }
+
+func (s *stringSliceValue) Get() interface{} { return []string(*s) }
+
+func (s *stringSliceValue) String() string {
+	return formatSlice(len(*s), func(i int) string { return (*s)[i] })
+}
+
+// -- []int Value
+type intSliceValue []int
+
+func newIntSliceValue(val []int, p *[]int) *intSliceValue {
+	*p = append([]int(nil), val...)
+	return (*intSliceValue)(p)
+}
+
+func (s *intSliceValue) Set(val string) error {
+	v, err := strconv.ParseInt(val, 0, strconv.IntSize)
+	if err != nil {
+		return numError(err)
+	}
+	*s = append(*s, int(v))
+	return nil
+}
+
+func (s *intSliceValue) Reset() { *s = nil }
+
+func (s *intSliceValue) IsRepeatable() bool { return true }
+
+func (s *intSliceValue) Get() interface{} { return []int(*s) }
+
+func (s *intSliceValue) String() string {
+	return formatSlice(len(*s), func(i int) string { return strconv.Itoa((*s)[i]) })
+}
+
+// -- []time.Duration Value
+type durationSliceValue []time.Duration
+
+func newDurationSliceValue(val []time.Duration, p *[]time.Duration) *durationSliceValue {
+	*p = append([]time.Duration(nil), val...)
+	return (*durationSliceValue)(p)
+}
+
+func (s *durationSliceValue) Set(val string) error {
+	v, err := time.ParseDuration(val)
+	if err != nil {
+		return errParse
+	}
+	*s = append(*s, v)
+	return nil
+}
+
+func (s *durationSliceValue) Reset() { *s = nil }
+
+func (s *durationSliceValue) IsRepeatable() bool { return true }
+
+func (s *durationSliceValue) Get() interface{} { return []time.Duration(*s) }
+
+func (s *durationSliceValue) String() string {
+	return formatSlice(len(*s), func(i int) string { return (*s)[i].String() })
+}
+
+// -- []Value Value
+type valueSliceValue struct {
+	p        *[]Value
+	newValue func() Value
+}
+
+func (s *valueSliceValue) Set(val string) error {
+	v := s.newValue()
+	if err := v.Set(val); err != nil {
+		return err
+	}
+	*s.p = append(*s.p, v)
+	return nil
+}
+
+func (s *valueSliceValue) Reset() { *s.p = nil }
+
+func (s *valueSliceValue) IsRepeatable() bool { return true }
+
+func (s *valueSliceValue) Get() interface{} { return *s.p }
+
+func (s *valueSliceValue) String() string {
+	if s.p == nil {
+		return "[]"
+	}
+	return formatSlice(len(*s.p), func(i int) string { return (*s.p)[i].String() })
+}
+
+// StringSliceVar defines a repeatable string flag with specified name, default value, and usage string.
+// The argument p points to a []string variable in which to store the values of the flag.
+func (f *FlagSet) StringSliceVar(p *[]string, name rune, value []string, usage string) {
+	f.Var(newStringSliceValue(value, p), name, usage)
+}
+
+// StringSliceVar defines a repeatable string flag with specified name, default value, and usage string.
+// The argument p points to a []string variable in which to store the values of the flag.
+func StringSliceVar(p *[]string, name rune, value []string, usage string) {
+	CommandLine.Var(newStringSliceValue(value, p), name, usage)
+}
+
+// StringSlice defines a repeatable string flag with specified name, default value, and usage string.
+// The return value is the address of a []string variable that stores the values of the flag.
+func (f *FlagSet) StringSlice(name rune, value []string, usage string) *[]string {
+	p := new([]string)
+	f.StringSliceVar(p, name, value, usage)
+	return p
+}
+
+// StringSlice defines a repeatable string flag with specified name, default value, and usage string.
+// The return value is the address of a []string variable that stores the values of the flag.
+func StringSlice(name rune, value []string, usage string) *[]string {
+	return CommandLine.StringSlice(name, value, usage)
+}
+
+// StringSliceVarLong defines a repeatable string flag with specified name, long name, default value, and usage string.
+// The argument p points to a []string variable in which to store the values of the flag.
+func (f *FlagSet) StringSliceVarLong(p *[]string, name rune, long string, value []string, usage string) {
+	f.VarLong(newStringSliceValue(value, p), name, long, usage)
+}
+
+// StringSliceVarLong defines a repeatable string flag with specified name, long name, default value, and usage string.
+// The argument p points to a []string variable in which to store the values of the flag.
+func StringSliceVarLong(p *[]string, name rune, long string, value []string, usage string) {
+	CommandLine.VarLong(newStringSliceValue(value, p), name, long, usage)
+}
+
+// StringSliceLong defines a repeatable string flag with specified name, long name, default value, and usage string.
+// The return value is the address of a []string variable that stores the values of the flag.
+func (f *FlagSet) StringSliceLong(name rune, long string, value []string, usage string) *[]string {
+	p := new([]string)
+	f.StringSliceVarLong(p, name, long, value, usage)
+	return p
+}
+
+// StringSliceLong defines a repeatable string flag with specified name, long name, default value, and usage string.
+// The return value is the address of a []string variable that stores the values of the flag.
+func StringSliceLong(name rune, long string, value []string, usage string) *[]string {
+	return CommandLine.StringSliceLong(name, long, value, usage)
+}
+
+// IntSliceVar defines a repeatable int flag with specified name, default value, and usage string.
+// The argument p points to a []int variable in which to store the values of the flag.
+func (f *FlagSet) IntSliceVar(p *[]int, name rune, value []int, usage string) {
+	f.Var(newIntSliceValue(value, p), name, usage)
+}
+
+// IntSliceVar defines a repeatable int flag with specified name, default value, and usage string.
+// The argument p points to a []int variable in which to store the values of the flag.
+func IntSliceVar(p *[]int, name rune, value []int, usage string) {
+	CommandLine.Var(newIntSliceValue(value, p), name, usage)
+}
+
+// IntSlice defines a repeatable int flag with specified name, default value, and usage string.
+// The return value is the address of a []int variable that stores the values of the flag.
+func (f *FlagSet) IntSlice(name rune, value []int, usage string) *[]int {
+	p := new([]int)
+	f.IntSliceVar(p, name, value, usage)
+	return p
+}
+
+// IntSlice defines a repeatable int flag with specified name, default value, and usage string.
+// The return value is the address of a []int variable that stores the values of the flag.
+func IntSlice(name rune, value []int, usage string) *[]int {
+	return CommandLine.IntSlice(name, value, usage)
+}
+
+// IntSliceVarLong defines a repeatable int flag with specified name, long name, default value, and usage string.
+// The argument p points to a []int variable in which to store the values of the flag.
+func (f *FlagSet) IntSliceVarLong(p *[]int, name rune, long string, value []int, usage string) {
+	f.VarLong(newIntSliceValue(value, p), name, long, usage)
+}
+
+// IntSliceVarLong defines a repeatable int flag with specified name, long name, default value, and usage string.
+// The argument p points to a []int variable in which to store the values of the flag.
+func IntSliceVarLong(p *[]int, name rune, long string, value []int, usage string) {
+	CommandLine.VarLong(newIntSliceValue(value, p), name, long, usage)
+}
+
+// IntSliceLong defines a repeatable int flag with specified name, long name, default value, and usage string.
+// The return value is the address of a []int variable that stores the values of the flag.
+func (f *FlagSet) IntSliceLong(name rune, long string, value []int, usage string) *[]int {
+	p := new([]int)
+	f.IntSliceVarLong(p, name, long, value, usage)
+	return p
+}
+
+// IntSliceLong defines a repeatable int flag with specified name, long name, default value, and usage string.
+// The return value is the address of a []int variable that stores the values of the flag.
+func IntSliceLong(name rune, long string, value []int, usage string) *[]int {
+	return CommandLine.IntSliceLong(name, long, value, usage)
+}
+
+// DurationSliceVar defines a repeatable time.Duration flag with specified name, default value, and usage string.
+// The argument p points to a []time.Duration variable in which to store the values of the flag.
+// The flag accepts values acceptable to time.ParseDuration.
+func (f *FlagSet) DurationSliceVar(p *[]time.Duration, name rune, value []time.Duration, usage string) {
+	f.Var(newDurationSliceValue(value, p), name, usage)
+}
+
+// DurationSliceVar defines a repeatable time.Duration flag with specified name, default value, and usage string.
+// The argument p points to a []time.Duration variable in which to store the values of the flag.
+// The flag accepts values acceptable to time.ParseDuration.
+func DurationSliceVar(p *[]time.Duration, name rune, value []time.Duration, usage string) {
+	CommandLine.Var(newDurationSliceValue(value, p), name, usage)
+}
+
+// DurationSlice defines a repeatable time.Duration flag with specified name, default value, and usage string.
+// The return value is the address of a []time.Duration variable that stores the values of the flag.
+// The flag accepts values acceptable to time.ParseDuration.
+func (f *FlagSet) DurationSlice(name rune, value []time.Duration, usage string) *[]time.Duration {
+	p := new([]time.Duration)
+	f.DurationSliceVar(p, name, value, usage)
+	return p
+}
+
+// DurationSlice defines a repeatable time.Duration flag with specified name, default value, and usage string.
+// The return value is the address of a []time.Duration variable that stores the values of the flag.
+// The flag accepts values acceptable to time.ParseDuration.
+func DurationSlice(name rune, value []time.Duration, usage string) *[]time.Duration {
+	return CommandLine.DurationSlice(name, value, usage)
+}
+
+// DurationSliceVarLong defines a repeatable time.Duration flag with specified name, long name, default value, and usage string.
+// The argument p points to a []time.Duration variable in which to store the values of the flag.
+// The flag accepts values acceptable to time.ParseDuration.
+func (f *FlagSet) DurationSliceVarLong(p *[]time.Duration, name rune, long string, value []time.Duration, usage string) {
+	f.VarLong(newDurationSliceValue(value, p), name, long, usage)
+}
+
+// DurationSliceVarLong defines a repeatable time.Duration flag with specified name, long name, default value, and usage string.
+// The argument p points to a []time.Duration variable in which to store the values of the flag.
+// The flag accepts values acceptable to time.ParseDuration.
+func DurationSliceVarLong(p *[]time.Duration, name rune, long string, value []time.Duration, usage string) {
+	CommandLine.VarLong(newDurationSliceValue(value, p), name, long, usage)
+}
+
+// DurationSliceLong defines a repeatable time.Duration flag with specified name, long name, default value, and usage string.
+// The return value is the address of a []time.Duration variable that stores the values of the flag.
+// The flag accepts values acceptable to time.ParseDuration.
+func (f *FlagSet) DurationSliceLong(name rune, long string, value []time.Duration, usage string) *[]time.Duration {
+	p := new([]time.Duration)
+	f.DurationSliceVarLong(p, name, long, value, usage)
+	return p
+}
+
+// DurationSliceLong defines a repeatable time.Duration flag with specified name, long name, default value, and usage string.
+// The return value is the address of a []time.Duration variable that stores the values of the flag.
+// The flag accepts values acceptable to time.ParseDuration.
+func DurationSliceLong(name rune, long string, value []time.Duration, usage string) *[]time.Duration {
+	return CommandLine.DurationSliceLong(name, long, value, usage)
+}
+
+// SliceVar defines a repeatable flag with specified name and usage string.
+// Each occurrence of the flag sets a new Value, returned by newValue, and
+// appends it to the slice p points to, which holds the default values.
+func (f *FlagSet) SliceVar(p *[]Value, name rune, newValue func() Value, usage string) {
+	f.Var(&valueSliceValue{p, newValue}, name, usage)
+}
+
+// SliceVar defines a repeatable flag with specified name and usage string.
+// Each occurrence of the flag sets a new Value, returned by newValue, and
+// appends it to the slice p points to, which holds the default values.
+func SliceVar(p *[]Value, name rune, newValue func() Value, usage string) {
+	CommandLine.Var(&valueSliceValue{p, newValue}, name, usage)
+}
+
+// SliceVarLong defines a repeatable flag with specified name, long name and usage string.
+// Each occurrence of the flag sets a new Value, returned by newValue, and
+// appends it to the slice p points to, which holds the default values.
+func (f *FlagSet) SliceVarLong(p *[]Value, name rune, long string, newValue func() Value, usage string) {
+	f.VarLong(&valueSliceValue{p, newValue}, name, long, usage)
+}
+
+// SliceVarLong defines a repeatable flag with specified name, long name and usage string.
+// Each occurrence of the flag sets a new Value, returned by newValue, and
+// appends it to the slice p points to, which holds the default values.
+func SliceVarLong(p *[]Value, name rune, long string, newValue func() Value, usage string) {
+	CommandLine.VarLong(&valueSliceValue{p, newValue}, name, long, usage)
+}
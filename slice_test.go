@@ -0,0 +1,85 @@
+package oldflag
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestStringSlice(t *testing.T) {
+	tests := []struct {
+		config string
+		args   []string
+		want   string
+	}{
+		{"", nil, "[a b]"},
+		{"", []string{"-Ic", "--include", "d"}, "[c d]"},
+		{"", []string{"-Ic,d", "-I", "e"}, "[c d e]"},
+		{"include = x\ninclude = y\n", nil, "[x y]"},
+		{"include = x\n", []string{"-Ic"}, "[c]"},
+	}
+	for _, tt := range tests {
+		f := NewFlagSet("test", ContinueOnError)
+		p := f.StringSliceLong('I', "include", []string{"a", "b"}, "search `dir`")
+		f.Lookup('I').Separator = ","
+		if err := f.ParseINI(strings.NewReader(tt.config), "config"); err != nil {
+			t.Fatal(err)
+		}
+		if err := f.Parse(tt.args); err != nil {
+			t.Fatal(err)
+		}
+		if got := fmt.Sprint(*p); got != tt.want {
+			t.Errorf("config %q, args %q: got %s, want %s", tt.config, tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestStringSliceReparse(t *testing.T) {
+	// Parse, then ParseFile, then Parse again with the same arguments,
+	// as when the configuration file is named by a flag.
+	f := NewFlagSet("test", ContinueOnError)
+	p := f.StringSlice('I', nil, "search `dir`")
+	m := f.StringMap('D', nil, "define `key=value`")
+	f.Lookup('D').Duplicates = RejectDuplicate
+	args := []string{"-I", "a", "-DK=1"}
+	if err := f.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.ParseINI(strings.NewReader("I = x\n"), "config"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(*p, *m); got != "[a] map[K:1]" {
+		t.Errorf("after parsing twice: got %s, want [a] map[K:1]", got)
+	}
+}
+
+// builderValue is a Value with an unrelated Reset method, from
+// strings.Builder, that does not make it repeatable.
+type builderValue struct{ strings.Builder }
+
+func (b *builderValue) Set(s string) error {
+	_, err := b.WriteString(s)
+	return err
+}
+
+func TestResetNotRepeatable(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	var b builderValue
+	b.WriteString("x")
+	f.Var(&b, 'b', "")
+	if err := f.Parse([]string{"-b", "y", "-b", "z"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != "xyz" {
+		t.Errorf("-b y -b z: got %s, want xyz", got)
+	}
+	var out strings.Builder
+	f.SetOutput(&out)
+	f.PrintDefaults()
+	if strings.Contains(out.String(), "repeatable") {
+		t.Errorf("PrintDefaults marks a Value with a Reset method repeatable:\n%s", out.String())
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package oldflag
+
+import "io"
+
+// terminalColumns returns 0: the width of the terminal is only known on
+// Linux.
+func terminalColumns(w io.Writer) int {
+	return 0
+}
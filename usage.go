@@ -0,0 +1,185 @@
+package oldflag
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Synopsis returns the synopsis line of the usage message of the set, in
+// the style of the classic Unix tools, such as
+//	usage: tool [-av] [-c count] [-o file] file ...
+// The boolean flags with rune names are clustered together; every other
+// flag is listed by itself, by its rune name if it has one, with its
+// argument named as by UnquoteUsage. The operands are described by
+// ArgsUsage, or by the declared operands, as in src ... [dst]. Lines longer
+// than the width of the terminal the output of the set goes to, or than
+// $COLUMNS or 80 if it is not a terminal, are wrapped, and the continuation
+// lines are indented to line up with the first flag. The result ends with a
+// newline.
+func (f *FlagSet) Synopsis() string {
+	prefix := "usage: "
+	if prog := f.programName(); prog != "" {
+		prefix += prog + " "
+	}
+	var words []string
+	for _, item := range synopsis(f) {
+		words = append(words, item.String())
+	}
+	if operands := f.argsUsage(); operands != "" {
+		words = append(words, operands)
+	}
+	return wrapWords(prefix, words, terminalWidth(f.Output()))
+}
+
+// Synopsis returns the synopsis line of the usage message for the
+// command-line flags. See FlagSet.Synopsis for details.
+func Synopsis() string {
+	return CommandLine.Synopsis()
+}
+
+// A synopsisItem is one item of a synopsis: a flag, or a cluster of
+// boolean flags, and the name of its argument, if any, or a group of
+// alternative flags. Items are bracketed unless they are required.
+type synopsisItem struct {
+	flag     string         // the flag, such as -o or --output, or a cluster such as -av
+	arg      string         // the name of the argument, or ""
+	optional bool           // whether the argument is optional
+	required bool           // whether the flag, or one of the group, is required
+	group    []synopsisItem // the alternatives of a group
+}
+
+// synopsis returns the items of the synopsis of the flags of f. The boolean
+// flags with rune names come first, clustered in one item, followed by the
+// other flags, each in its own item, spelled by rune name if they have one.
+// Required flags are never clustered, and flags that are alternatives, as
+// declared by MutuallyExclusive or ExactlyOne, are listed as a group, at
+// the place of the first of them.
+func synopsis(f *FlagSet) []synopsisItem {
+	var items []synopsisItem
+	cluster := "-"
+	listed := make(map[*constraint]bool)
+	for _, flag := range sortFlags(f.flags) {
+		c := f.group(flag)
+		switch {
+		case c != nil:
+			if listed[c] {
+				continue
+			}
+			listed[c] = true
+			group := synopsisItem{required: c.kind == exactlyOne}
+			for _, member := range c.flags {
+				item := flagItem(member)
+				item.required = true
+				group.group = append(group.group, item)
+			}
+			items = append(items, group)
+		case flag.Name != 0 && flag.kind() == kindBool && !flag.Required:
+			cluster += string(flag.Name)
+		default:
+			items = append(items, flagItem(flag))
+		}
+	}
+	if cluster != "-" {
+		items = append([]synopsisItem{{flag: cluster}}, items...)
+	}
+	return items
+}
+
+// flagItem returns the synopsis item of flag by itself.
+func flagItem(flag *Flag) synopsisItem {
+	item := synopsisItem{flag: flag.spelling(), required: flag.Required}
+	if flag.kind() != kindBool {
+		item.arg, _ = UnquoteUsage(flag)
+		if item.arg == "" {
+			item.arg = "value"
+		}
+		item.optional = flag.Optional
+	}
+	return item
+}
+
+// String returns the item as plain text, such as [-c count].
+func (item synopsisItem) String() string {
+	return item.format(func(s string, arg bool) string { return s })
+}
+
+// format returns the item with its flags and arguments marked up by mark.
+func (item synopsisItem) format(mark func(s string, arg bool) string) string {
+	var s string
+	if item.group != nil {
+		alts := make([]string, len(item.group))
+		for i, alt := range item.group {
+			alts[i] = alt.format(mark)
+		}
+		s = strings.Join(alts, " | ")
+		if item.required {
+			return "{" + s + "}"
+		}
+		return "[" + s + "]"
+	}
+
+	flag, arg := mark(item.flag, false), mark(item.arg, true)
+	long := strings.HasPrefix(item.flag, "--")
+	switch {
+	case item.arg == "":
+		s = flag
+	case item.optional && long:
+		s = flag + "[=" + arg + "]"
+	case item.optional:
+		s = flag + "[" + arg + "]"
+	case long:
+		s = flag + "=" + arg
+	default:
+		s = flag + " " + arg
+	}
+	if item.required {
+		return s
+	}
+	return "[" + s + "]"
+}
+
+// wrapWords joins words with spaces after prefix, breaking the lines
+// between words so that they fit in width columns if possible. Continuation
+// lines are indented by the width of prefix, or by 8 columns if that would
+// leave less than half of the line.
+func wrapWords(prefix string, words []string, width int) string {
+	indent := displayWidth(prefix)
+	if indent > width/2 {
+		indent = 8
+	}
+	var b strings.Builder
+	b.WriteString(prefix)
+	col := displayWidth(prefix)
+	for i, word := range words {
+		n := displayWidth(word)
+		if i > 0 {
+			if col+1+n > width {
+				fmt.Fprintf(&b, "\n%s", strings.Repeat(" ", indent))
+				col = indent
+			} else {
+				b.WriteByte(' ')
+				col++
+			}
+		}
+		b.WriteString(word)
+		col += n
+	}
+	b.WriteByte('\n')
+	return b.String()
+}
+
+// terminalWidth returns the number of columns usage messages written to w
+// are wrapped at: the width of the terminal if w is one, $COLUMNS if it is
+// set to a positive number, or 80.
+func terminalWidth(w io.Writer) int {
+	if n := terminalColumns(w); n > 0 {
+		return n
+	}
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		return n
+	}
+	return 80
+}
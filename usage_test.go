@@ -0,0 +1,148 @@
+package oldflag
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestSynopsis(t *testing.T) {
+	f := NewFlagSet("/usr/bin/tool", ContinueOnError)
+	f.SetOutput(io.Discard)
+	f.Bool('v', false, "verbose")
+	f.Bool('a', false, "all")
+	f.Int('c', 3, "stop after `count` lines")
+	f.StringLong('o', "output", "-", "write to `file`")
+	f.BoolLong(0, "dry-run", false, "do nothing")
+	f.IntLong('g', "debug", 0, "debug `level`")
+	f.Lookup('g').Optional = true
+	f.ArgsUsage = "file ..."
+
+	t.Setenv("COLUMNS", "")
+	want := "usage: tool [-av] [-c count] [--dry-run] [-g[level]] [-o file] file ...\n"
+	if got := f.Synopsis(); got != want {
+		t.Errorf("Synopsis() = %q, want %q", got, want)
+	}
+
+	t.Setenv("COLUMNS", "40")
+	want = "usage: tool [-av] [-c count] [--dry-run]\n" +
+		"            [-g[level]] [-o file]\n" +
+		"            file ...\n"
+	if got := f.Synopsis(); got != want {
+		t.Errorf("Synopsis() with COLUMNS=40 = %q, want %q", got, want)
+	}
+}
+
+func TestRequired(t *testing.T) {
+	f := NewFlagSet("tool", ContinueOnError)
+	f.SetOutput(io.Discard)
+	f.Bool('a', false, "all")
+	f.StringLong('o', "output", "", "write to `file`")
+	f.StringLong(0, "name", "", "the `name`")
+	f.Lookup('o').Required = true
+	f.LookupLong("name").Required = true
+	f.LookupLong("name").Env = "TEST_NAME"
+
+	t.Setenv("COLUMNS", "")
+	want := "usage: tool [-a] --name=name -o file\n"
+	if got := f.Synopsis(); got != want {
+		t.Errorf("Synopsis() = %q, want %q", got, want)
+	}
+	want = "missing required flags: --name, -o"
+	if err := f.Parse([]string{"-a"}); err == nil || err.Error() != want {
+		t.Errorf("Parse without required flags: got %v, want %s", err, want)
+	}
+	t.Setenv("TEST_NAME", "x")
+	if err := f.Parse([]string{"-o", "out"}); err != nil {
+		t.Errorf("Parse with required flags: %v", err)
+	}
+}
+
+func TestConstraints(t *testing.T) {
+	newFlagSet := func() *FlagSet {
+		f := NewFlagSet("tool", ContinueOnError)
+		f.SetOutput(io.Discard)
+		for _, r := range "abvupxy" {
+			f.Bool(r, false, "")
+		}
+		f.String('o', "", "write to `file`")
+		f.MutuallyExclusive('a', 'b', 'o')
+		f.ExactlyOne('x', 'y')
+		f.Requires('u', 'p')
+		return f
+	}
+
+	t.Setenv("COLUMNS", "")
+	want := "usage: tool [-puv] [-a | -b | -o file] {-x | -y}\n"
+	if got := newFlagSet().Synopsis(); got != want {
+		t.Errorf("Synopsis() = %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-x"}, "<nil>"},
+		{[]string{"-ay", "-up"}, "<nil>"},
+		{[]string{"-abx"}, "-a, -b cannot be used together"},
+		{[]string{"-a", "-ofile", "-u"}, "-a, -o cannot be used together; one of -x, -y is required; -u requires -p"},
+		{[]string{"-xy"}, "-x, -y cannot be used together"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(newFlagSet().Parse(tt.args)); got != tt.want {
+			t.Errorf("Parse(%q) = %s, want %s", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestGroups(t *testing.T) {
+	f := NewFlagSet("tool", ContinueOnError)
+	var b strings.Builder
+	f.SetOutput(&b)
+	f.BoolLong(0, "port", false, "listen on a port")
+	f.Bool('v', false, "verbose")
+	f.Bool('a', false, "all")
+	f.Lookup('a').Group = "Network"
+	f.LookupLong("port").Group = "Network"
+	f.Description = "Tool does things."
+	f.Epilog = "See tool(7)."
+
+	t.Setenv("COLUMNS", "")
+	f.Usage()
+	want := "usage: tool [-av] [--port]\n" +
+		"\n" +
+		"Tool does things.\n" +
+		"\n" +
+		"  -v          verbose\n" +
+		"\n" +
+		"Network:\n" +
+		"  -a          all\n" +
+		"      --port  listen on a port\n" +
+		"\n" +
+		"See tool(7).\n"
+	if got := b.String(); got != want {
+		t.Errorf("Usage() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestPrintDefaults(t *testing.T) {
+	f := NewFlagSet("tool", ContinueOnError)
+	var b strings.Builder
+	f.SetOutput(&b)
+	f.IntLong('x', "extra", 7, "stop after `count` lines, or never if count is zero")
+	f.String('名', "", "the name")
+	f.StringLong(0, "a-very-long-flag-name", "", "too long")
+
+	t.Setenv("COLUMNS", "50")
+	f.PrintDefaults()
+	want := "      --a-very-long-flag-name string\n" +
+		"                     too long\n" +
+		"  -x, --extra count  stop after count lines, or\n" +
+		"                     never if count is zero\n" +
+		"                     (default 7)\n" +
+		"  -名 string         the name\n"
+	if got := b.String(); got != want {
+		t.Errorf("PrintDefaults() =\n%s\nwant\n%s", got, want)
+	}
+}